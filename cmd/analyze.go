@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/analysis"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+var (
+	analyzeSearch   bool
+	analyzeLimit    int
+	analyzeOutliers int
+)
+
+func init() {
+	analyzeCmd := withScopes(&cobra.Command{
+		Use:   "analyze <playlist|album|search query>",
+		Short: "Show the audio profile of a playlist, album or search",
+		Long: `Analyze the audio features of a playlist, an album or the results of a search.
+
+Reports histograms of tempo, energy, valence, danceability, acousticness and
+loudness, the key/mode breakdown, how tracks fall into the four mood quadrants
+(valence × energy), tracks that stand out from the rest, and a "vibe" phrase
+you can feed straight back into 'moodify search'.
+
+The argument is tried as an album link/URI, then as one of your playlists (by
+name, ID or link), and otherwise used as a search query.
+
+Examples:
+  moodify analyze "Road Trip"
+  moodify analyze https://open.spotify.com/album/1DFixLWuPkv3KT3TnV35m3
+  moodify analyze --search late night jazz
+  moodify analyze "Road Trip" --output json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runAnalyze,
+	}, scopePlaylistReadPrivate, "user-read-private")
+
+	analyzeCmd.Flags().BoolVarP(&analyzeSearch, "search", "s", false, "Treat the arguments as a search query")
+	analyzeCmd.Flags().IntVarP(&analyzeLimit, "limit", "n", 50, "Number of search results to analyze (max 50)")
+	analyzeCmd.Flags().IntVar(&analyzeOutliers, "outliers", 5, "Maximum number of outlier tracks to show")
+	addOutputFlag(analyzeCmd)
+
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	client := spotifyClient(cmd)
+
+	ref := strings.Join(args, " ")
+	source, tracks, err := loadTrackSource(ctx, client, ref, analyzeSearch, analyzeLimit)
+	if err != nil {
+		return err
+	}
+	if len(tracks) == 0 {
+		fmt.Printf("📭 No tracks found for \"%s\"\n", ref)
+		return nil
+	}
+
+	if !jsonOutput() {
+		fmt.Printf("🔬 Analyzing %d tracks from %s...\n", len(tracks), source)
+		fmt.Println()
+	}
+
+	ids := make([]spotify.ID, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.ID)
+	}
+
+	features, err := spotifyx.GetAudioFeaturesBatch(ctx, client, ids)
+	if err != nil {
+		fmt.Println("❌ Couldn't get audio features. Spotify only offers them to some apps.")
+		return err
+	}
+
+	analyzed := make([]analysis.Track, 0, len(tracks))
+	for _, track := range tracks {
+		artist := "Unknown Artist"
+		if len(track.Artists) > 0 {
+			artist = track.Artists[0].Name
+		}
+		analyzed = append(analyzed, analysis.Track{
+			ID:       track.ID,
+			Name:     track.Name,
+			Artist:   artist,
+			Features: features[track.ID],
+		})
+	}
+
+	report := analysis.Analyze(source, analyzed, analyzeOutliers)
+
+	if jsonOutput() {
+		return printJSON(report)
+	}
+
+	printAnalysisReport(report)
+	return nil
+}
+
+// loadTrackSource resolves ref to an album, one of the user's playlists or a
+// search query, in that order, and returns a description and its tracks
+func loadTrackSource(ctx context.Context, client *spotify.Client, ref string, forceSearch bool, searchLimit int) (string, []spotify.SimpleTrack, error) {
+	if !forceSearch {
+		if id, ok := spotifyx.ParseID(ref, "album"); ok && strings.Contains(ref, "album") {
+			album, tracks, err := spotifyx.AlbumTracks(ctx, client, id)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to get album: %w", err)
+			}
+			return fmt.Sprintf("album \"%s\"", album.Name), tracks, nil
+		}
+
+		playlist, err := spotifyx.ResolvePlaylist(ctx, client, ref)
+		if err == nil {
+			full, err := spotifyx.PlaylistTracks(ctx, client, playlist.ID)
+			if err != nil {
+				return "", nil, err
+			}
+			tracks := make([]spotify.SimpleTrack, 0, len(full))
+			for _, track := range full {
+				tracks = append(tracks, track.SimpleTrack)
+			}
+			return fmt.Sprintf("playlist \"%s\"", playlist.Name), tracks, nil
+		}
+		if !errors.Is(err, spotifyx.ErrPlaylistNotFound) {
+			return "", nil, err
+		}
+	}
+
+	if searchLimit < 1 || searchLimit > 50 {
+		searchLimit = 50
+	}
+
+	results, err := client.Search(ctx, ref, spotify.SearchTypeTrack, spotify.Limit(searchLimit))
+	if err != nil {
+		return "", nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	var tracks []spotify.SimpleTrack
+	if results.Tracks != nil {
+		for _, track := range results.Tracks.Tracks {
+			tracks = append(tracks, track.SimpleTrack)
+		}
+	}
+	return fmt.Sprintf("search \"%s\"", ref), tracks, nil
+}
+
+// printAnalysisReport renders an audio profile as text charts
+func printAnalysisReport(report *analysis.Report) {
+	fmt.Printf("🔬 Audio Profile: %s\n", report.Source)
+	fmt.Println("═══════════════════════════════════")
+	fmt.Println()
+
+	if report.Analyzed == 0 {
+		fmt.Println("📭 None of these tracks have audio features")
+		return
+	}
+
+	if report.Analyzed < report.Tracks {
+		fmt.Printf("⚠️  Audio features were only available for %d of %d tracks\n\n", report.Analyzed, report.Tracks)
+	}
+
+	if avg := report.Averages; avg != nil {
+		fmt.Printf("📊 Averages: %.0f BPM • energy %.2f • valence %.2f • danceability %.2f • acousticness %.2f • %.1f dB\n",
+			avg.Tempo, avg.Energy, avg.Valence, avg.Danceability, avg.Acousticness, avg.Loudness)
+		fmt.Println()
+	}
+
+	for _, h := range report.Histograms {
+		fmt.Printf("📈 %s\n", h.Feature)
+		rows := make([]barChartRow, 0, len(h.Buckets))
+		for _, bucket := range h.Buckets {
+			rows = append(rows, barChartRow{Label: bucket.Label, Value: bucket.Value})
+		}
+		printBarChart(rows, 0, 24)
+		fmt.Println()
+	}
+
+	if len(report.Keys) > 0 {
+		fmt.Printf("🎹 Keys (%.0f%% major, %.0f%% minor)\n", report.MajorShare*100, (1-report.MajorShare)*100)
+		keys := report.Keys
+		if len(keys) > 6 {
+			keys = keys[:6]
+		}
+		rows := make([]barChartRow, 0, len(keys))
+		for _, key := range keys {
+			rows = append(rows, barChartRow{Label: key.Label, Value: key.Value})
+		}
+		printBarChart(rows, 0, 24)
+		fmt.Println()
+	}
+
+	fmt.Println("🎭 Mood Quadrants (valence × energy)")
+	rows := make([]barChartRow, 0, len(report.Quadrants))
+	for _, q := range report.Quadrants {
+		rows = append(rows, barChartRow{
+			Label:   q.Name,
+			Value:   q.Share,
+			Display: fmt.Sprintf("%3.0f%% (%d)", q.Share*100, q.Tracks),
+		})
+	}
+	printBarChart(rows, 1.0, 24)
+	fmt.Println()
+
+	if len(report.Outliers) > 0 {
+		fmt.Println("🚩 Outliers")
+		for _, o := range report.Outliers {
+			direction := "high"
+			if o.ZScore < 0 {
+				direction = "low"
+			}
+			fmt.Printf("   • %s — %s  (unusually %s %s: %s)\n",
+				o.Track, o.Artist, direction, strings.ToLower(o.Feature), formatFeatureValue(o.Feature, o.Value))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("✨ Vibe: %s\n", report.Vibe)
+	fmt.Println()
+	fmt.Println("💡 Tips:")
+	fmt.Printf("   • Find more like this: moodify search \"%s\"\n", report.Vibe)
+	fmt.Println("   • Export the full profile: --output json")
+}
+
+// formatFeatureValue renders a feature value with its unit
+func formatFeatureValue(feature string, value float64) string {
+	switch feature {
+	case "Tempo":
+		return fmt.Sprintf("%.0f BPM", value)
+	case "Loudness":
+		return fmt.Sprintf("%.1f dB", value)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/analysis"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/internal/tui"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// browseMoreLikeThis is how many recommendations "more like this" fetches
+const browseMoreLikeThis = 10
+
+// browseHelp is the key legend shown at the bottom of the browser
+const browseHelp = "↑/↓ move • space select • a all • p play • u queue • d remove • m more like this • s save • e add to playlist • q quit"
+
+// browserItem is a track in the browser and whether it's selected
+type browserItem struct {
+	track    spotify.SimpleTrack
+	selected bool
+}
+
+// trackBrowser is the interactive result list behind --interactive
+type trackBrowser struct {
+	ctx      context.Context
+	client   *spotify.Client
+	svc      *moodify.Service
+	term     *tui.Terminal
+	title    string
+	items    []browserItem
+	features map[spotify.ID]*spotify.AudioFeatures
+	cursor   int
+	offset   int
+	status   string
+	prompt   string
+	input    string
+}
+
+// browseTracks shows tracks in an interactive list until the user quits. It
+// returns tui.ErrUnavailable when there's no terminal to draw on, so callers
+// can fall back to printing the list.
+func browseTracks(ctx context.Context, client *spotify.Client, title string, tracks []spotify.SimpleTrack) error {
+	term, err := tui.Open()
+	if err != nil {
+		return err
+	}
+	defer term.Close()
+
+	b := &trackBrowser{ctx: ctx, client: client, svc: newService(client), term: term, title: title, features: map[spotify.ID]*spotify.AudioFeatures{}}
+	b.add(len(b.items), tracks)
+	return b.run()
+}
+
+// tryBrowseTracks runs the interactive browser, reporting false when there's
+// no terminal so the caller can print the tracks as a list instead
+func tryBrowseTracks(ctx context.Context, client *spotify.Client, title string, tracks []spotify.SimpleTrack) (bool, error) {
+	err := browseTracks(ctx, client, title, tracks)
+	if errors.Is(err, tui.ErrUnavailable) {
+		fmt.Println("⚠️  Interactive mode needs a terminal, listing the tracks instead")
+		return false, nil
+	}
+	return true, err
+}
+
+// run handles key presses until the user quits
+func (b *trackBrowser) run() error {
+	for {
+		b.draw()
+		ev, err := b.term.ReadEvent()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case ev.Key == tui.KeyCtrlC || ev.Key == tui.KeyEscape || ev.Rune == 'q':
+			return nil
+		case ev.Key == tui.KeyUp || ev.Rune == 'k':
+			b.move(-1)
+		case ev.Key == tui.KeyDown || ev.Rune == 'j':
+			b.move(1)
+		case ev.Key == tui.KeyPageUp:
+			b.move(-b.pageSize())
+		case ev.Key == tui.KeyPageDown:
+			b.move(b.pageSize())
+		case ev.Key == tui.KeyHome:
+			b.move(-len(b.items))
+		case ev.Key == tui.KeyEnd:
+			b.move(len(b.items))
+		case ev.Rune == ' ':
+			if len(b.items) > 0 {
+				b.items[b.cursor].selected = !b.items[b.cursor].selected
+				b.move(1)
+			}
+		case ev.Rune == 'a':
+			b.toggleAll()
+		case ev.Rune == 'd' || ev.Key == tui.KeyDelete:
+			b.remove()
+		case ev.Rune == 'p':
+			b.play()
+		case ev.Rune == 'u':
+			b.queue()
+		case ev.Rune == 'm':
+			b.moreLikeThis()
+		case ev.Rune == 's':
+			b.saveNew()
+		case ev.Rune == 'e':
+			b.saveExisting()
+		}
+	}
+}
+
+// draw renders the list, the highlighted track's audio features, the status
+// line and the key legend
+func (b *trackBrowser) draw() {
+	width, _ := b.term.Size()
+	selected := len(b.selection(false))
+
+	lines := []string{
+		fmt.Sprintf("🎧 %s  (%d tracks, %d selected)", b.title, len(b.items), selected),
+		strings.Repeat("─", min(width, 60)),
+	}
+
+	page := b.pageSize()
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	} else if b.cursor >= b.offset+page {
+		b.offset = b.cursor - page + 1
+	}
+	for i := b.offset; i < len(b.items) && i < b.offset+page; i++ {
+		item := b.items[i]
+		pointer, check := "  ", "[ ]"
+		if i == b.cursor {
+			pointer = "▶ "
+		}
+		if item.selected {
+			check = "[x]"
+		}
+		label := fmt.Sprintf("%s%s %2d. %s", pointer, check, i+1, formatTrackLabel(item.track))
+		label = truncateText(width-8, label)
+		lines = append(lines, fmt.Sprintf("%s  %s", label, formatPlaybackDuration(item.track.TimeDuration())))
+	}
+	for i := len(lines); i < page+2; i++ {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, strings.Repeat("─", min(width, 60)), b.preview())
+	if b.prompt != "" {
+		lines = append(lines, fmt.Sprintf("%s %s▌  (Enter to confirm, Esc to cancel)", b.prompt, b.input))
+	} else {
+		lines = append(lines, b.status)
+	}
+	lines = append(lines, truncateText(width, browseHelp))
+
+	b.term.Draw(lines)
+}
+
+// pageSize is how many tracks fit on screen around the header and footer
+func (b *trackBrowser) pageSize() int {
+	_, height := b.term.Size()
+	return max(height-6, 3)
+}
+
+// preview summarises the highlighted track's audio features
+func (b *trackBrowser) preview() string {
+	if len(b.items) == 0 {
+		return "📭 No tracks left"
+	}
+	f := b.features[b.items[b.cursor].track.ID]
+	if f == nil {
+		return "🎛️  Audio features unavailable for this track"
+	}
+
+	key := int(f.Key)
+	mode := int(f.Mode)
+	return fmt.Sprintf("🎛️  %s %s (%s) • %.0f BPM • energy %.2f • dance %.2f • mood %.2f • acoustic %.2f",
+		analysis.KeyName(key), analysis.ModeName(mode), analysis.CamelotCode(key, mode),
+		f.Tempo, f.Energy, f.Danceability, f.Valence, f.Acousticness)
+}
+
+func (b *trackBrowser) move(delta int) {
+	b.cursor = max(0, min(b.cursor+delta, len(b.items)-1))
+}
+
+func (b *trackBrowser) toggleAll() {
+	all := len(b.selection(false)) < len(b.items)
+	for i := range b.items {
+		b.items[i].selected = all
+	}
+}
+
+// selection returns the selected tracks; with fallback, the highlighted track
+// when nothing is selected
+func (b *trackBrowser) selection(fallback bool) []spotify.SimpleTrack {
+	var tracks []spotify.SimpleTrack
+	for _, item := range b.items {
+		if item.selected {
+			tracks = append(tracks, item.track)
+		}
+	}
+	if len(tracks) == 0 && fallback && len(b.items) > 0 {
+		tracks = append(tracks, b.items[b.cursor].track)
+	}
+	return tracks
+}
+
+// busy shows a status message before a slow request
+func (b *trackBrowser) busy(status string) {
+	b.status = status
+	b.draw()
+}
+
+func (b *trackBrowser) remove() {
+	if len(b.items) == 0 {
+		return
+	}
+	removed := b.items[b.cursor].track
+	b.items = append(b.items[:b.cursor], b.items[b.cursor+1:]...)
+	b.move(0)
+	b.status = fmt.Sprintf("🗑️  Removed %s", removed.Name)
+}
+
+func (b *trackBrowser) play() {
+	tracks := b.selection(true)
+	if len(tracks) == 0 {
+		return
+	}
+	b.busy(fmt.Sprintf("▶️  Starting playback of %d tracks...", len(tracks)))
+	if err := b.svc.Play(b.ctx, tracks); err != nil {
+		b.status = fmt.Sprintf("❌ Failed to start playback: %s", errs.WithHint(errs.ClassifyPlayback(err)))
+		return
+	}
+	b.status = fmt.Sprintf("✅ Playing %d tracks", len(tracks))
+}
+
+func (b *trackBrowser) queue() {
+	tracks := b.selection(true)
+	if len(tracks) == 0 {
+		return
+	}
+	b.busy(fmt.Sprintf("➕ Adding %d tracks to your queue...", len(tracks)))
+	queued, err := b.svc.Queue(b.ctx, tracks)
+	if err != nil {
+		b.status = fmt.Sprintf("❌ Queued %d of %d tracks: %s", queued, len(tracks), errs.WithHint(errs.ClassifyPlayback(err)))
+		return
+	}
+	b.status = fmt.Sprintf("✅ Added %d tracks to your queue", queued)
+}
+
+// moreLikeThis inserts recommendations seeded by the highlighted track below it
+func (b *trackBrowser) moreLikeThis() {
+	if len(b.items) == 0 {
+		return
+	}
+	seed := b.items[b.cursor].track
+	b.busy(fmt.Sprintf("🔍 Finding more like %s...", seed.Name))
+
+	recs, err := b.client.GetRecommendations(b.ctx, spotify.Seeds{Tracks: []spotify.ID{seed.ID}},
+		spotify.NewTrackAttributes(), spotify.Limit(browseMoreLikeThis*2), spotify.Market(b.svc.Market()))
+	if err != nil {
+		b.status = fmt.Sprintf("❌ Failed to get recommendations: %v", err)
+		return
+	}
+
+	seen := make(map[spotify.ID]bool, len(b.items))
+	for _, item := range b.items {
+		seen[item.track.ID] = true
+	}
+	var fresh []spotify.SimpleTrack
+	for _, t := range recs.Tracks {
+		if !seen[t.ID] && len(fresh) < browseMoreLikeThis {
+			seen[t.ID] = true
+			fresh = append(fresh, t)
+		}
+	}
+	if len(fresh) == 0 {
+		b.status = "😔 No new tracks like that one"
+		return
+	}
+
+	b.add(b.cursor+1, fresh)
+	b.status = fmt.Sprintf("➕ Added %d tracks like %s", len(fresh), seed.Name)
+}
+
+// add inserts tracks at position and fetches their audio features
+func (b *trackBrowser) add(position int, tracks []spotify.SimpleTrack) {
+	items := make([]browserItem, 0, len(tracks))
+	ids := make([]spotify.ID, 0, len(tracks))
+	for _, t := range tracks {
+		items = append(items, browserItem{track: t})
+		ids = append(ids, t.ID)
+	}
+	b.items = append(b.items[:position], append(items, b.items[position:]...)...)
+
+	// Previews are a nice-to-have; the list works without them
+	if features, err := spotifyx.GetAudioFeaturesBatch(b.ctx, b.client, ids); err == nil {
+		for id, f := range features {
+			b.features[id] = f
+		}
+	}
+}
+
+func (b *trackBrowser) saveNew() {
+	tracks := b.selection(true)
+	if len(tracks) == 0 {
+		return
+	}
+	name, ok := b.ask(fmt.Sprintf("💾 New playlist name for %d tracks:", len(tracks)))
+	if !ok {
+		return
+	}
+	b.busy(fmt.Sprintf("💾 Saving to %s...", name))
+	if _, err := b.svc.SavePlaylist(b.ctx, name, tracks, moodify.SavePlaylistOptions{}); err != nil {
+		b.status = fmt.Sprintf("❌ Failed to create playlist: %v", err)
+		return
+	}
+	b.status = fmt.Sprintf("✅ Created private playlist '%s' with %d tracks", name, len(tracks))
+}
+
+func (b *trackBrowser) saveExisting() {
+	tracks := b.selection(true)
+	if len(tracks) == 0 {
+		return
+	}
+	ref, ok := b.ask(fmt.Sprintf("📂 Add %d tracks to playlist (name or link):", len(tracks)))
+	if !ok {
+		return
+	}
+	b.busy(fmt.Sprintf("🔍 Finding %s...", ref))
+
+	playlist, err := spotifyx.ResolvePlaylist(b.ctx, b.client, ref)
+	var ambiguous *spotifyx.AmbiguousPlaylistError
+	switch {
+	case errors.As(err, &ambiguous):
+		b.status = fmt.Sprintf("❓ %d playlists match %q, be more specific or use a link", len(ambiguous.Matches), ref)
+		return
+	case err != nil:
+		b.status = fmt.Sprintf("❌ %v", err)
+		return
+	}
+
+	ids := make([]spotify.ID, 0, len(tracks))
+	for _, t := range tracks {
+		ids = append(ids, t.ID)
+	}
+	if err := spotifyx.AddTracksInBatches(b.ctx, b.client, playlist.ID, ids); err != nil {
+		b.status = fmt.Sprintf("❌ Failed to add tracks: %v", err)
+		return
+	}
+	b.status = fmt.Sprintf("✅ Added %d tracks to '%s'", len(tracks), playlist.Name)
+}
+
+// ask reads a line of text in the prompt area; ok is false if cancelled
+func (b *trackBrowser) ask(prompt string) (string, bool) {
+	b.prompt, b.input = prompt, ""
+	defer func() { b.prompt, b.input = "", "" }()
+
+	for {
+		b.draw()
+		ev, err := b.term.ReadEvent()
+		if err != nil {
+			return "", false
+		}
+		switch ev.Key {
+		case tui.KeyEnter:
+			if text := strings.TrimSpace(b.input); text != "" {
+				return text, true
+			}
+		case tui.KeyEscape, tui.KeyCtrlC:
+			b.status = ""
+			return "", false
+		case tui.KeyBackspace:
+			if runes := []rune(b.input); len(runes) > 0 {
+				b.input = string(runes[:len(runes)-1])
+			}
+		case tui.KeyRune:
+			b.input += string(ev.Rune)
+		}
+	}
+}
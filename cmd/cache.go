@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/spf13/cobra"
+)
+
+var clearExpiredOnly bool
+
+func init() {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear cached Spotify responses",
+		Long: `moodify caches Spotify responses that rarely change so repeated commands are
+faster and use less of your rate limit:
+
+  audio-features, audio-analysis   30 days
+  genre-seeds                      7 days
+  artists                          1 day
+  top                              6 hours
+  search                           10 minutes
+
+Player, playlist and recommendation requests always go to Spotify. Stale entries
+are revalidated with their ETag where Spotify provides one, and responses marked
+no-store are never cached.
+
+Skip the cache for one command with --no-cache, or fetch and cache fresh
+responses with --refresh.`,
+	}
+
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show what is cached per endpoint",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheStats,
+	}
+	addOutputFlag(statsCmd)
+
+	clearCmd := &cobra.Command{
+		Use:   "clear [endpoint...]",
+		Short: "Remove cached responses",
+		Long: `Remove cached Spotify responses, optionally only for some endpoints.
+
+Examples:
+  moodify cache clear
+  moodify cache clear search top
+  moodify cache clear --expired`,
+		RunE: runCacheClear,
+	}
+	clearCmd.Flags().BoolVar(&clearExpiredOnly, "expired", false, "Only remove responses that are no longer fresh")
+
+	cacheCmd.AddCommand(statsCmd, clearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	stats, err := spotifyx.ResponseCacheStats()
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return printJSON(stats)
+	}
+
+	fmt.Printf("🗄️  Response cache: %s\n", stats.Dir)
+	fmt.Printf("   %d entries, %s\n\n", stats.Entries, formatBytes(stats.Bytes))
+
+	fmt.Printf("   %-16s %8s %8s %8s %10s  %s\n", "ENDPOINT", "TTL", "ENTRIES", "FRESH", "SIZE", "OLDEST")
+	for _, e := range stats.Endpoints {
+		ttl := "-"
+		if e.TTLSecs > 0 {
+			ttl = formatTTL(time.Duration(e.TTLSecs) * time.Second)
+		}
+		oldest := "-"
+		if e.Oldest != nil {
+			oldest = formatTTL(time.Since(*e.Oldest)) + " ago"
+		}
+		fmt.Printf("   %-16s %8s %8d %8d %10s  %s\n", e.Endpoint, ttl, e.Entries, e.Fresh, formatBytes(e.Bytes), oldest)
+	}
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	known := map[string]bool{}
+	var names []string
+	for _, rule := range spotifyx.CacheRules {
+		known[rule.Endpoint] = true
+		names = append(names, rule.Endpoint)
+	}
+	for _, arg := range args {
+		if !known[arg] {
+			return fmt.Errorf("unknown endpoint %q (use %s)", arg, strings.Join(names, ", "))
+		}
+	}
+
+	removed, err := spotifyx.ClearResponseCache(clearExpiredOnly, args...)
+	if err != nil {
+		return err
+	}
+
+	what := "cached responses"
+	if clearExpiredOnly {
+		what = "expired responses"
+	}
+	fmt.Printf("🧹 Removed %d %s\n", removed, what)
+	return nil
+}
+
+// formatTTL renders a cache lifetime or age in its largest whole unit
+func formatTTL(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+// formatBytes renders a size in B, KB or MB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
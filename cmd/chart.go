@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// barChartRow is one labelled bar in a text chart
+type barChartRow struct {
+	Label   string
+	Value   float64
+	Display string // text shown after the bar, defaults to the value
+}
+
+// printBarChart prints horizontal bars scaled to max, or to the largest value when max is 0
+func printBarChart(rows []barChartRow, max float64, width int) {
+	if max <= 0 {
+		for _, row := range rows {
+			if row.Value > max {
+				max = row.Value
+			}
+		}
+	}
+
+	labelWidth := 0
+	for _, row := range rows {
+		if n := utf8.RuneCountInString(row.Label); n > labelWidth {
+			labelWidth = n
+		}
+	}
+
+	for _, row := range rows {
+		display := row.Display
+		if display == "" {
+			display = fmt.Sprintf("%.0f", row.Value)
+		}
+		padding := strings.Repeat(" ", labelWidth-utf8.RuneCountInString(row.Label))
+		fmt.Printf("   %s%s  %s  %s\n", row.Label, padding, renderBar(row.Value, max, width), display)
+	}
+}
+
+// renderBar draws a fixed-width bar filled in proportion to value/max
+func renderBar(value, max float64, width int) string {
+	filled := 0
+	if max > 0 {
+		filled = int(value/max*float64(width) + 0.5)
+	}
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("·", width-filled)
+}
+
+// printEnergyCurve plots planned and achieved energy (0-1) over time, one
+// column per time slice. Negative values are treated as unknown.
+func printEnergyCurve(planned, achieved []float64, height int) {
+	step := 1.0 / float64(height)
+	for row := 0; row < height; row++ {
+		low := 1.0 - float64(row+1)*step
+
+		var b strings.Builder
+		for col := range achieved {
+			filled := achieved[col] >= 0 && achieved[col] >= low+step/2
+			onPlan := planned[col] >= low && (planned[col] < low+step || row == 0)
+			switch {
+			case filled && onPlan:
+				b.WriteString("▓")
+			case filled:
+				b.WriteString("█")
+			case onPlan:
+				b.WriteString("─")
+			default:
+				b.WriteString(" ")
+			}
+		}
+
+		label := "   "
+		switch row {
+		case 0:
+			label = "1.0"
+		case height / 2:
+			label = "0.5"
+		case height - 1:
+			label = "0.0"
+		}
+		fmt.Printf("   %s ┤%s\n", label, b.String())
+	}
+	fmt.Printf("       └%s\n", strings.Repeat("─", len(achieved)))
+	fmt.Println("        █ achieved  ─ planned  ▓ on target")
+}
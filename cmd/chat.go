@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+// chatDroppedShown is how many dropped tracks are named after a refinement
+const chatDroppedShown = 3
+
+// chatHelp lists the commands available in a chat session
+const chatHelp = `Commands:
+  /filters       Show the current filters
+  /list          Show the current results again
+  /undo          Go back to the previous results
+  /new <vibe>    Start over with a new vibe
+  /save <name>   Save the results to a new playlist
+  /play          Play the results
+  /queue         Add the results to your queue
+  /quit          Leave the chat`
+
+var chatLimit int
+
+// chatState is one step of a chat session: the filters and what they found
+type chatState struct {
+	query   string
+	filters ai.Filters
+	tracks  []spotify.SimpleTrack
+}
+
+func init() {
+	chatCmd := withScopes(&cobra.Command{
+		Use:   "chat [vibe]",
+		Short: "Refine a search conversationally",
+		Long: `Start a conversation about what you want to hear, then steer the results with
+follow-ups like "more upbeat", "no rap", "older stuff" or "only jazz".
+
+Each follow-up changes the current filters rather than starting over. With
+OPENAI_API_KEY set the follow-ups are interpreted by the AI model, otherwise by
+built-in rules. After every change moodify fetches new results and shows what
+changed.
+
+` + chatHelp + `
+
+Examples:
+  moodify chat chill indie
+  moodify chat --limit 25`,
+		RunE: runChat,
+	}, scopePlaylistModifyPrivate, scopePlaylistModifyPublic, "user-read-private", "user-top-read", "user-modify-playback-state")
+
+	chatCmd.Flags().IntVarP(&chatLimit, "limit", "n", 15, "Number of tracks per result set (1-100)")
+
+	rootCmd.AddCommand(chatCmd)
+}
+
+func runChat(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if chatLimit < 1 || chatLimit > 100 {
+		return fmt.Errorf("--limit must be between 1 and 100")
+	}
+
+	client := spotifyClient(cmd)
+	svc := newService(client)
+
+	var history []chatState
+	var current *chatState
+
+	// start replaces the current results with a fresh search for vibe
+	start := func(vibe string) {
+		next, err := fetchChatState(ctx, svc, vibe, moodify.Parse(ctx, vibe).Filters)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		if current != nil {
+			history = append(history, *current)
+		}
+		current = next
+		printChatResults(current, nil)
+	}
+
+	fmt.Println("💬 Moodify chat - describe a vibe, then refine it. Type /help for commands.")
+	if len(args) > 0 {
+		start(strings.Join(args, " "))
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("\n🎧 > ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			command, arg, _ := strings.Cut(line, " ")
+			command, arg = strings.ToLower(command), strings.TrimSpace(arg)
+
+			switch command {
+			case "/quit", "/exit", "/q":
+				return nil
+			case "/help":
+				fmt.Println(chatHelp)
+			case "/new":
+				if arg == "" {
+					fmt.Println("Usage: /new <vibe>")
+					continue
+				}
+				start(arg)
+			case "/undo":
+				if len(history) == 0 {
+					fmt.Println("Nothing to undo")
+					continue
+				}
+				previous := history[len(history)-1]
+				history = history[:len(history)-1]
+				if current != nil {
+					printFilterChanges(current.filters, previous.filters)
+				}
+				current = &previous
+				fmt.Println("↩️  Back to the previous results")
+				printChatResults(current, nil)
+			case "/filters":
+				if current == nil {
+					fmt.Println("No vibe yet - describe what you'd like to hear")
+					continue
+				}
+				printParsedFilters(current.filters)
+			case "/list":
+				if current != nil {
+					printChatResults(current, nil)
+				}
+			case "/save":
+				if current == nil || arg == "" {
+					fmt.Println("Usage: /save <playlist name> (after describing a vibe)")
+					continue
+				}
+				if _, err := svc.SavePlaylist(ctx, arg, current.tracks, moodify.SavePlaylistOptions{}); err != nil {
+					fmt.Printf("❌ Failed to create playlist: %v\n", err)
+				} else {
+					fmt.Printf("✅ Created private playlist '%s' with %d tracks!\n", arg, len(current.tracks))
+				}
+			case "/play", "/queue":
+				if current == nil {
+					fmt.Println("No results yet - describe what you'd like to hear")
+					continue
+				}
+				applyPlaybackFlags(ctx, svc, current.tracks, command == "/queue", command == "/play")
+			default:
+				fmt.Printf("Unknown command %s - type /help for the list\n", command)
+			}
+			continue
+		}
+
+		if current == nil {
+			start(line)
+			continue
+		}
+
+		filters, err := ai.Refine(ctx, current.filters, line)
+		if errors.Is(err, ai.ErrNoChange) {
+			fmt.Println("🤷 I couldn't turn that into a change. Try \"more upbeat\", \"no rap\", \"older stuff\",")
+			fmt.Println("   \"only jazz\", or start over with /new <vibe>")
+			continue
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+
+		next, err := fetchChatState(ctx, svc, current.query, filters)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		printFilterChanges(current.filters, next.filters)
+		history = append(history, *current)
+		printChatResults(next, current)
+		current = next
+	}
+}
+
+// fetchChatState finds tracks for the filters
+func fetchChatState(ctx context.Context, svc *moodify.Service, query string, filters ai.Filters) (*chatState, error) {
+	seeds := svc.Seeds(ctx, filters)
+	tracks, err := svc.Recommend(ctx, query, filters, seeds, chatLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &chatState{query: query, filters: filters, tracks: tracks}, nil
+}
+
+// printFilterChanges lists how the filters changed
+func printFilterChanges(before, after ai.Filters) {
+	changes := ai.Changes(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Println("🎚️  " + strings.Join(changes, "\n    "))
+}
+
+// printChatResults lists the results, marking tracks that weren't in the
+// previous set and summarising the ones that dropped out
+func printChatResults(state, previous *chatState) {
+	if len(state.tracks) == 0 {
+		fmt.Println("😔 Nothing matches these filters. Try loosening them or /undo")
+		return
+	}
+
+	before := map[spotify.ID]bool{}
+	if previous != nil {
+		for _, t := range previous.tracks {
+			before[t.ID] = true
+		}
+	}
+
+	after := map[spotify.ID]bool{}
+	added := 0
+	fmt.Println()
+	for i, t := range state.tracks {
+		after[t.ID] = true
+		marker := " "
+		if previous != nil && !before[t.ID] {
+			marker = "+"
+			added++
+		}
+		year := ""
+		if y := spotifyx.ParseYear(t.Album.ReleaseDate); y > 0 {
+			year = fmt.Sprintf("  (%d)", y)
+		}
+		fmt.Printf("%s %2d. %s%s\n", marker, i+1, formatTrackLabel(t), year)
+	}
+
+	if previous == nil {
+		return
+	}
+
+	var dropped []string
+	for _, t := range previous.tracks {
+		if !after[t.ID] {
+			dropped = append(dropped, t.Name)
+		}
+	}
+	fmt.Printf("\n➕ %d new • ➖ %d dropped • %d kept\n", added, len(dropped), len(state.tracks)-added)
+	if len(dropped) > 0 {
+		shown := dropped[:min(len(dropped), chatDroppedShown)]
+		more := ""
+		if len(dropped) > chatDroppedShown {
+			more = fmt.Sprintf(" and %d more", len(dropped)-chatDroppedShown)
+		}
+		fmt.Printf("   Dropped: %s%s\n", strings.Join(shown, ", "), more)
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/auth"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+// scopesAnnotation is the cobra annotation listing the scopes a command needs.
+// Commands that have it get a Spotify client from the root command's
+// PersistentPreRunE; see connect.
+const scopesAnnotation = "moodify/scopes"
+
+// unlimitedAnnotation marks commands that keep running until stopped, so the
+// per-command request budget doesn't apply to them. Its value lists the bool
+// flags that make the command keep running, or is empty when it always does.
+const unlimitedAnnotation = "moodify/unlimited-requests"
+
+// Scopes declared by commands outside the playlist family
+var (
+	statsScopes        = []string{"user-top-read", "user-read-private"}
+	discoveryScopes    = []string{"user-top-read", "playlist-modify-private", "user-read-private", "user-modify-playback-state"}
+	playbackReadScopes = []string{"user-read-currently-playing", "user-read-playback-state", "user-read-private"}
+)
+
+// clientKey is the command context key holding the Spotify client
+type clientKey struct{}
+
+// withScopes records the scopes a command needs in its annotations and help text
+func withScopes(cmd *cobra.Command, scopes ...string) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[scopesAnnotation] = strings.Join(scopes, " ")
+
+	long := cmd.Long
+	if long == "" {
+		long = cmd.Short + "."
+	}
+	cmd.Long = long + "\n\nRequired scopes: " + strings.Join(scopes, ", ")
+	return cmd
+}
+
+// unlimitedRequests marks a command that keeps running until stopped, either
+// always or when one of the given bool flags is set
+func unlimitedRequests(cmd *cobra.Command, flags ...string) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[unlimitedAnnotation] = strings.Join(flags, " ")
+	return cmd
+}
+
+// runsUntilStopped reports whether the command, as invoked, was marked with
+// unlimitedRequests
+func runsUntilStopped(cmd *cobra.Command) bool {
+	flags, ok := cmd.Annotations[unlimitedAnnotation]
+	if !ok {
+		return false
+	}
+	if flags == "" {
+		return true
+	}
+	for _, name := range strings.Fields(flags) {
+		if set, err := cmd.Flags().GetBool(name); err == nil && set {
+			return true
+		}
+	}
+	return false
+}
+
+// commandScopes returns the scopes recorded by withScopes
+func commandScopes(cmd *cobra.Command) []string {
+	return strings.Fields(cmd.Annotations[scopesAnnotation])
+}
+
+// authConfig returns the auth configuration for a command needing scopes
+func authConfig(scopes []string) *auth.Config {
+	config := auth.ConfigWithClientID(auth.GetClientIDFromEnv())
+	config.Scopes = scopes
+	return config
+}
+
+// connect builds the Spotify client for commands that declare scopes, and
+// stores it in the command's context. Missing logins, expired tokens and
+// missing scopes fail here, the same way for every command.
+func connect(cmd *cobra.Command) error {
+	// Transports copy the policy when they're built, so lift the budget first
+	if runsUntilStopped(cmd) {
+		spotifyx.DefaultRetryPolicy.Budget = 0
+	}
+	if _, ok := cmd.Annotations[scopesAnnotation]; !ok {
+		return nil
+	}
+
+	// A refresh token Spotify already rejected won't work now either
+	if token := auth.CheckToken(); token.State == auth.TokenRefreshFailed {
+		return errs.Wrap(errs.ErrNotAuthenticated, fmt.Errorf("your Spotify login has expired (%s)", token.RefreshError))
+	}
+
+	scopes := commandScopes(cmd)
+	ctx := cmd.Context()
+	// Refreshes the token when it has expired
+	client, err := auth.GetAuthenticatedClient(ctx, authConfig(scopes))
+	if err != nil {
+		return err
+	}
+	if err := requireScopes(scopes...); err != nil {
+		return err
+	}
+
+	cmd.SetContext(context.WithValue(ctx, clientKey{}, client))
+	return nil
+}
+
+// requireScopes fails with errs.ErrMissingScope when the saved login wasn't
+// granted all of scopes. Logins saved before scopes were recorded pass, and
+// Spotify rejects the calls they can't make.
+func requireScopes(scopes ...string) error {
+	granted, known, err := auth.GrantedScopes()
+	if err != nil || !known {
+		return err
+	}
+
+	var missing []string
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return errs.Wrap(errs.ErrMissingScope, fmt.Errorf("your login doesn't grant %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// spotifyClient returns the client connect stored for the command. It panics
+// for commands that don't declare scopes, which is a programming error.
+func spotifyClient(cmd *cobra.Command) *spotify.Client {
+	client, ok := cmd.Context().Value(clientKey{}).(*spotify.Client)
+	if !ok {
+		panic(fmt.Sprintf("moodify %s has no Spotify client; declare its scopes with withScopes", cmd.Name()))
+	}
+	return client
+}
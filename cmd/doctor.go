@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lorrehuggan/moodify/internal/auth"
+	"github.com/lorrehuggan/moodify/internal/doctor"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/spf13/cobra"
+)
+
+// spotifyAccountsURL is where the clock check gets Spotify's time from
+const spotifyAccountsURL = "https://accounts.spotify.com/"
+
+func init() {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose your setup, login and connections",
+		Long: `Run a suite of checks and report each as pass, warn or fail, with what to do
+about anything that isn't right.
+
+Local checks:
+• Config directory exists, is writable and not writable by others
+• Token file is private (mode 600) and readable
+• Clock is in step with Spotify's
+• Login callback ports are free
+
+Connection checks:
+• The refresh token still works (this refreshes your access token)
+• Your Spotify profile can be fetched, and every scope moodify uses was granted
+• The recommendations endpoint answers
+• The OpenAI API key works, if one is set
+
+The command fails if any check fails. Attach the JSON report to bug tickets;
+it contains no tokens or keys:
+  moodify doctor -o json > doctor.json`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+	addOutputFlag(doctorCmd)
+
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if !jsonOutput() {
+		fmt.Println("🩺 Moodify Doctor")
+		fmt.Println("════════════════")
+		fmt.Println()
+	}
+
+	groups := []struct {
+		title string
+		run   func() []doctor.Result
+	}{
+		{"💻 Local", func() []doctor.Result { return doctor.Local(ctx, spotifyAccountsURL) }},
+		{"🎧 Spotify", func() []doctor.Result { return doctor.Spotify(ctx, authConfig(auth.DefaultScopes)) }},
+		{"🤖 AI", func() []doctor.Result { return []doctor.Result{doctor.AIProvider(ctx)} }},
+	}
+
+	var results []doctor.Result
+	for _, group := range groups {
+		groupResults := group.run()
+		results = append(results, groupResults...)
+		if !jsonOutput() {
+			printDoctorGroup(group.title, groupResults)
+		}
+	}
+
+	clientID := "custom"
+	if auth.GetClientIDFromEnv() == auth.DefaultClientID {
+		clientID = "shared"
+	}
+	report := doctor.NewReport(clientID, results)
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		s := report.Summary
+		fmt.Printf("📋 %d passed, %d warnings, %d failed, %d skipped\n", s.Pass, s.Warn, s.Fail, s.Skip)
+		fmt.Println("   For a bug report: moodify doctor -o json > doctor.json")
+	}
+
+	if !report.Healthy() {
+		// Every failure has been reported above
+		return exitError{code: errs.ExitFailure}
+	}
+	return nil
+}
+
+// printDoctorGroup prints one group of check results with their remediations
+func printDoctorGroup(title string, results []doctor.Result) {
+	fmt.Println(title)
+	for _, result := range results {
+		fmt.Printf("   %s %-36s %s\n", doctorIcon(result.Status), result.Name, result.Message)
+		if result.Remediation != "" && result.Status != doctor.Pass {
+			fmt.Printf("      💡 %s\n", result.Remediation)
+		}
+	}
+	fmt.Println()
+}
+
+func doctorIcon(status doctor.Status) string {
+	switch status {
+	case doctor.Pass:
+		return "✅"
+	case doctor.Warn:
+		return "⚠️ "
+	case doctor.Fail:
+		return "❌"
+	}
+	return "➖"
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+var (
+	targetDuration    time.Duration
+	durationTolerance time.Duration
+	durationFit       string
+)
+
+// addDurationFlags adds --duration, --tolerance and --fit to a command
+func addDurationFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&targetDuration, "duration", 0, "Target total length, e.g. 35m (picks tracks to fit instead of --limit)")
+	cmd.Flags().DurationVar(&durationTolerance, "tolerance", 90*time.Second, "How far the total length may be from --duration")
+	cmd.Flags().StringVar(&durationFit, "fit", moodify.BestFit, "How to pick tracks for --duration: best-fit or first-fit")
+}
+
+// validateDurationFlags checks the --duration flags before any requests are made
+func validateDurationFlags() error {
+	if targetDuration < 0 {
+		return fmt.Errorf("--duration must be positive")
+	}
+	if durationFit != moodify.BestFit && durationFit != moodify.FirstFit {
+		return fmt.Errorf("invalid --fit %q (use %s or %s)", durationFit, moodify.BestFit, moodify.FirstFit)
+	}
+	return nil
+}
+
+// durationOptions returns the fit set by the --duration flags
+func durationOptions() moodify.Fit {
+	return moodify.Fit{
+		Target:    targetDuration,
+		Tolerance: durationTolerance,
+		Method:    durationFit,
+	}
+}
+
+// printRuntime reports the total length of the tracks against --duration
+func printRuntime(tracks []spotify.SimpleTrack) {
+	total := moodify.Runtime(tracks)
+	if targetDuration <= 0 {
+		fmt.Printf("⏱️  Total runtime: %s\n", formatRuntime(total))
+		return
+	}
+
+	fmt.Printf("⏱️  Total runtime: %s (target %s, %s)\n", formatRuntime(total), formatRuntime(targetDuration), durationFit)
+	gap := total - targetDuration
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > durationTolerance {
+		fmt.Printf("   ⚠️  Couldn't get within %s of the target. Try a broader query or a larger --tolerance\n",
+			formatRuntime(durationTolerance))
+	}
+}
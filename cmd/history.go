@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/history"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+var (
+	historySince    string
+	historyUntil    string
+	historyArtist   string
+	historyTrack    string
+	historyLimit    int
+	recordInterval  time.Duration
+	recordMinListen time.Duration
+)
+
+func init() {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show your locally recorded listening history",
+		Long: `Show plays recorded by 'moodify history record'.
+
+Spotify only remembers your last 50 plays, so moodify can keep its own history.
+Filter by date range, artist or track name:
+
+Examples:
+  moodify history --since 7d
+  moodify history --since 2024-01-01 --until 2024-01-31 --artist radiohead
+  moodify history --track "karma police" --output json
+
+Dates accept YYYY-MM-DD, RFC 3339 timestamps, 'today', or a relative age such as 12h, 7d or 4w.`,
+		RunE: runHistory,
+	}
+
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only show plays after this date or age (e.g. 2024-01-01, 7d)")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "Only show plays before this date or age")
+	historyCmd.Flags().StringVar(&historyArtist, "artist", "", "Only show plays by artists matching this text")
+	historyCmd.Flags().StringVar(&historyTrack, "track", "", "Only show tracks matching this text")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 50, "Number of most recent plays to show (0 for all)")
+	addOutputFlag(historyCmd)
+
+	recordCmd := unlimitedRequests(withScopes(&cobra.Command{
+		Use:   "record",
+		Short: "Record everything you listen to until stopped",
+		Long: `Watch your Spotify playback and append every completed play to your local history.
+
+Each play stores the track, when it was played, how long you listened, the device
+and the playlist or album it was played from. Tracks skipped before --min-listen
+are not recorded. Leave it running in a spare terminal, tmux pane or as a service;
+stop it with Ctrl-C.`,
+		RunE: runHistoryRecord,
+	}, playbackReadScopes...))
+
+	recordCmd.Flags().DurationVar(&recordInterval, "interval", 5*time.Second, "How often to poll Spotify")
+	recordCmd.Flags().DurationVar(&recordMinListen, "min-listen", 30*time.Second, "Minimum listening time for a play to be recorded")
+
+	historyCmd.AddCommand(recordCmd, newSearchesCmd())
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	since, err := parseTimeBound(historySince, now, false)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseTimeBound(historyUntil, now, true)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	store, err := history.Open()
+	if err != nil {
+		return err
+	}
+
+	plays, err := store.Query(history.Query{
+		Since:  since,
+		Until:  until,
+		Artist: historyArtist,
+		Track:  historyTrack,
+		Limit:  historyLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if plays == nil {
+			plays = []history.Play{}
+		}
+		return printJSON(plays)
+	}
+
+	fmt.Println("📜 Listening History")
+	fmt.Println("════════════════════")
+	fmt.Println()
+
+	if len(plays) == 0 {
+		fmt.Println("📭 No plays found")
+		fmt.Println()
+		fmt.Println("💡 Tips:")
+		fmt.Println("   • Start recording: moodify history record")
+		fmt.Println("   • Loosen your --since, --artist or --track filters")
+		return nil
+	}
+
+	var total time.Duration
+	lastDay := ""
+	for _, play := range plays {
+		ended := play.EndedAt.Local()
+		day := ended.Format("Monday, 2006-01-02")
+		if day != lastDay {
+			if lastDay != "" {
+				fmt.Println()
+			}
+			fmt.Printf("📅 %s\n", day)
+			lastDay = day
+		}
+
+		listened := time.Duration(play.ListenedMs) * time.Millisecond
+		duration := time.Duration(play.DurationMs) * time.Millisecond
+		total += listened
+
+		fmt.Printf("   %s  %s — %s  (%s of %s)",
+			ended.Format("15:04"), play.Track, play.Artist(),
+			formatPlaybackDuration(listened), formatPlaybackDuration(duration))
+		if play.Device != "" {
+			fmt.Printf("  📱 %s", play.Device)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 %d plays, %s of listening\n", len(plays), formatListeningTime(total))
+	return nil
+}
+
+func runHistoryRecord(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client := spotifyClient(cmd)
+
+	store, err := history.Open()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🎙️  Recording listening history (Ctrl-C to stop)")
+	fmt.Printf("   Saving to: %s\n", store.Path())
+	fmt.Println()
+
+	tracker := &history.Tracker{MinListen: recordMinListen}
+	recorded := 0
+	save := func(play *history.Play) {
+		if play == nil {
+			return
+		}
+		if err := store.Append(*play); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+			return
+		}
+		recorded++
+		fmt.Printf("✅ %s  %s — %s  (%s listened)\n",
+			play.EndedAt.Local().Format("15:04"), play.Track, play.Artist(),
+			formatPlaybackDuration(time.Duration(play.ListenedMs)*time.Millisecond))
+	}
+
+	opts := spotifyx.WatchOptions{
+		Interval:    recordInterval,
+		MaxInterval: time.Minute,
+		FullState:   true,
+		OnError: func(err error, retryIn time.Duration) {
+			fmt.Fprintf(os.Stderr, "⚠️  Failed to get playback state: %v (retrying in %s)\n", err, retryIn)
+		},
+	}
+
+	err = spotifyx.Watch(ctx, client, opts, func(state *spotify.PlayerState) {
+		save(tracker.Observe(time.Now(), state))
+	})
+
+	// Keep the track that was playing when we were stopped
+	save(tracker.Flush(time.Now()))
+
+	fmt.Println()
+	fmt.Printf("👋 Stopped recording (%d plays saved this session)\n", recorded)
+	return err
+}
+
+// parseTimeBound parses --since/--until values: dates, timestamps, "today" or
+// relative ages like 12h, 7d and 4w. Date-only upper bounds cover the whole day.
+func parseTimeBound(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if value == "today" {
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if endOfDay {
+			return midnight.AddDate(0, 0, 1), nil
+		}
+		return midnight, nil
+	}
+
+	if n := len(value); n > 1 {
+		if days, err := strconv.Atoi(value[:n-1]); err == nil {
+			switch value[n-1] {
+			case 'd':
+				return now.AddDate(0, 0, -days), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*days), nil
+			}
+		}
+	}
+
+	if age, err := time.ParseDuration(value); err == nil {
+		return now.Add(-age), nil
+	}
+
+	if day, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		if endOfDay {
+			return day.AddDate(0, 0, 1), nil
+		}
+		return day, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(value)); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised date %q (use YYYY-MM-DD, 'today' or an age like 7d)", value)
+}
+
+// formatListeningTime renders a total listening time as "2h 05m" or "12m"
+func formatListeningTime(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -68,11 +68,7 @@ func runManualLogin(ctx context.Context, cmd *cobra.Command, args []string) erro
 		ClientID:    finalClientID,
 		RedirectURI: fmt.Sprintf("http://127.0.0.1:%s/callback", port),
 		Port:        port,
-		Scopes: []string{
-			"user-top-read",
-			"playlist-modify-private",
-			"user-read-private",
-		},
+		Scopes:      auth.DefaultScopes,
 	}
 
 	// Check if port is available
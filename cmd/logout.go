@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/lorrehuggan/moodify/internal/auth"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
 	"github.com/spf13/cobra"
 )
 
@@ -19,5 +22,13 @@ commands that require Spotify authentication.`,
 }
 
 func runLogout(cmd *cobra.Command, args []string) error {
-	return auth.Logout()
+	if err := auth.Logout(); err != nil {
+		return err
+	}
+
+	// Cached top artists and searches belong to the account that logged out
+	if _, err := spotifyx.ClearResponseCache(false); err != nil {
+		fmt.Printf("⚠️  Failed to clear cached responses: %v\n", err)
+	}
+	return nil
 }
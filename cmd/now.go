@@ -3,24 +3,62 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/lorrehuggan/moodify/internal/auth"
 	"github.com/spf13/cobra"
 )
 
-var showExtendedInfo bool
+var (
+	showExtendedInfo bool
+	watchNow         bool
+	watchInterval    time.Duration
+	onChangeCommand  string
+	emitNDJSON       bool
+	nowFormat        string
+	nowCacheTTL      time.Duration
+)
 
 func init() {
-	nowCmd := &cobra.Command{
+	nowCmd := unlimitedRequests(withScopes(&cobra.Command{
 		Use:   "now",
 		Short: "Show what's currently playing on Spotify",
 		Long: `Display information about the currently playing track on your Spotify account.
-Shows track name, artist, album, progress, and playback controls information.`,
+Shows track name, artist, album, progress, and playback controls information.
+
+Use --watch to keep the display updated live. Combine it with --on-change to run a
+command whenever the track changes, or --ndjson to print one JSON event per change
+(handy for tmux, polybar and other status bars).
+
+Use --format to print a single line from a Go text/template, for example:
+  moodify now --format '{{.Artist}} – {{.Track}} [{{.Progress}}/{{.Duration}}]'
+  moodify now --format '{{.Track | truncate 25}} {{if .Playing}}▶{{else}}⏸{{end}}'
+  moodify now --format '{{.Track}}{{with .Features}} ({{printf "%.0f" .Tempo}} BPM){{end}}'
+
+Available fields: Track, Artist, Artists, Album, Year, URL, Playing, Status, Progress,
+Duration, ProgressMs, DurationMs, Percent, Device, DeviceType, Shuffle, Repeat, Volume
+and Features (Key, Mode, Tempo, Energy, Danceability, Valence, Loudness, Speechiness,
+Acousticness, Instrumentalness). Features is empty when Spotify has no audio features
+for the track, so use them inside {{with .Features}}...{{end}} as above.
+Template functions: truncate, upper, lower, join, bar.
+Formatted output is cached for --cache-ttl so frequent polling stays within rate limits.
+
+Use --output json to print the same fields as a JSON object (null when nothing is playing).`,
 		RunE: runNow,
-	}
+	}, playbackReadScopes...), "watch", "ndjson")
 
 	nowCmd.Flags().BoolVarP(&showExtendedInfo, "extended", "e", false, "Show extended track information (audio features)")
+	nowCmd.Flags().BoolVarP(&watchNow, "watch", "w", false, "Keep watching and update the display live (Ctrl-C to stop)")
+	nowCmd.Flags().DurationVar(&watchInterval, "interval", 2*time.Second, "How often to poll Spotify in --watch mode")
+	nowCmd.Flags().StringVar(&onChangeCommand, "on-change", "", "Command to run whenever the track changes in --watch mode")
+	nowCmd.Flags().BoolVar(&emitNDJSON, "ndjson", false, "Print one JSON event per track change instead of the live display")
+	nowCmd.Flags().StringVarP(&nowFormat, "format", "f", "", "Print a single line using a Go template (see help for fields)")
+	nowCmd.Flags().DurationVar(&nowCacheTTL, "cache-ttl", 5*time.Second, "How long --format output may be served from cache (0 to disable)")
+
+	addOutputFlag(nowCmd)
+	nowCmd.MarkFlagsMutuallyExclusive("output", "format")
+	nowCmd.MarkFlagsMutuallyExclusive("output", "watch")
+	nowCmd.MarkFlagsMutuallyExclusive("output", "ndjson")
 
 	rootCmd.AddCommand(nowCmd)
 }
@@ -28,38 +66,45 @@ Shows track name, artist, album, progress, and playback controls information.`,
 func runNow(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	// Check authentication
-	if !auth.QuickCheck() {
-		fmt.Println("🔐 Authentication required!")
-		fmt.Println("Run: moodify login")
-		return fmt.Errorf("not authenticated")
+	if err := validateOutputFormat(); err != nil {
+		return err
 	}
 
-	// Get authenticated client with playback scopes
-	config := &auth.Config{
-		ClientID:    auth.GetClientIDFromEnv(),
-		RedirectURI: "http://127.0.0.1:8808/callback",
-		Port:        "8808",
-		Scopes: []string{
-			"user-read-currently-playing",
-			"user-read-playback-state",
-			"user-read-private",
-		},
+	// Catch template mistakes before touching the network
+	if nowFormat != "" {
+		if _, err := renderNowFormat(nowFormat, nil); err != nil {
+			return err
+		}
 	}
 
-	client, err := auth.GetAuthenticatedClient(ctx, config)
-	if err != nil {
-		fmt.Println("❌ Authentication failed. Run: moodify login")
-		return err
+	// Status bars poll every second or so; serve them from a short-lived cache
+	if nowFormat != "" && !watchNow && !emitNDJSON {
+		if np, ok := loadNowPlayingCache(formatNeedsFeatures(nowFormat)); ok {
+			return printNowFormat(np)
+		}
 	}
 
-	// Get currently playing track
-	currently, err := client.PlayerCurrentlyPlaying(ctx)
+	client := spotifyClient(cmd)
+
+	if watchNow || emitNDJSON {
+		return runNowWatch(ctx, client)
+	}
+
+	np, err := newService(client).NowPlaying(ctx, showExtendedInfo || formatNeedsFeatures(nowFormat))
 	if err != nil {
 		return fmt.Errorf("failed to get currently playing track: %w", err)
 	}
 
-	if currently == nil || currently.Item == nil {
+	if jsonOutput() {
+		return printJSON(np)
+	}
+
+	if nowFormat != "" {
+		saveNowPlayingCache(np)
+		return printNowFormat(np)
+	}
+
+	if np == nil {
 		fmt.Println("🎵 Nothing is currently playing")
 		fmt.Println()
 		fmt.Println("💡 Tips:")
@@ -69,87 +114,51 @@ func runNow(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	track := currently.Item
 	fmt.Println("🎵 Now Playing")
 	fmt.Println("═══════════════")
 	fmt.Println()
 
 	// Basic track info
-	fmt.Printf("🎤 Track: %s\n", track.Name)
+	fmt.Printf("🎤 Track: %s\n", np.Track)
 
 	// Artist(s)
-	if len(track.Artists) > 0 {
-		if len(track.Artists) == 1 {
-			fmt.Printf("👤 Artist: %s\n", track.Artists[0].Name)
-		} else {
-			fmt.Print("👥 Artists: ")
-			for i, artist := range track.Artists {
-				if i > 0 {
-					fmt.Print(", ")
-				}
-				fmt.Print(artist.Name)
-			}
-			fmt.Println()
-		}
+	if len(np.Artists) == 1 {
+		fmt.Printf("👤 Artist: %s\n", np.Artist)
+	} else if len(np.Artists) > 1 {
+		fmt.Printf("👥 Artists: %s\n", strings.Join(np.Artists, ", "))
 	}
 
 	// Album info
-	fmt.Printf("💿 Album: %s", track.Album.Name)
-	if track.Album.ReleaseDate != "" {
-		if len(track.Album.ReleaseDate) >= 4 {
-			fmt.Printf(" (%s)", track.Album.ReleaseDate[:4])
-		}
+	fmt.Printf("💿 Album: %s", np.Album)
+	if np.Year != "" {
+		fmt.Printf(" (%s)", np.Year)
 	}
 	fmt.Println()
 
 	// Progress and duration
-	if track.Duration > 0 {
-		progress := time.Duration(currently.Progress) * time.Millisecond
-		duration := time.Duration(track.Duration) * time.Millisecond
-
-		fmt.Printf("⏰ Progress: %s / %s",
-			formatPlaybackDuration(progress),
-			formatPlaybackDuration(duration))
-
-		// Progress bar
-		if duration > 0 {
-			percentage := float64(currently.Progress) / float64(track.Duration) * 100
-			fmt.Printf(" (%.1f%%)", percentage)
-
-			// Visual progress bar
-			barLength := 30
-			filled := int(percentage / 100 * float64(barLength))
-			fmt.Print("\n    ")
-			for i := 0; i < barLength; i++ {
-				if i < filled {
-					fmt.Print("█")
-				} else {
-					fmt.Print("░")
-				}
-			}
-		}
-		fmt.Println()
+	if np.DurationMs > 0 {
+		fmt.Printf("⏰ Progress: %s / %s (%.1f%%)", np.Progress, np.Duration, np.Percent)
+		fmt.Printf("\n    %s\n", renderProgressBar(np.Percent, 30))
 	}
 
 	// Playback state
 	playState := "⏸️  Paused"
-	if currently.Playing {
+	if np.Playing {
 		playState = "▶️  Playing"
 	}
 	fmt.Printf("🔄 Status: %s\n", playState)
 
 	// Device info (if available)
-	playerState, err := client.PlayerState(ctx)
-	if err == nil && playerState != nil {
-		fmt.Printf("📱 Device: %s (%s)\n", playerState.Device.Name, playerState.Device.Type)
+	if np.Device != "" {
+		fmt.Printf("📱 Device: %s (%s)\n", np.Device, np.DeviceType)
 
-		if playerState.ShuffleState {
+		if np.Shuffle {
 			fmt.Print("🔀 Shuffle: On  ")
 		} else {
 			fmt.Print("🔀 Shuffle: Off  ")
 		}
 
-		switch playerState.RepeatState {
+		switch np.Repeat {
 		case "track":
 			fmt.Println("🔂 Repeat: Track")
 		case "context":
@@ -158,14 +167,14 @@ func runNow(cmd *cobra.Command, args []string) error {
 			fmt.Println("🔁 Repeat: Off")
 		}
 
-		if playerState.Device.Volume > 0 {
-			fmt.Printf("🔊 Volume: %d%%\n", playerState.Device.Volume)
+		if np.Volume > 0 {
+			fmt.Printf("🔊 Volume: %d%%\n", np.Volume)
 		}
 	}
 
 	// Spotify link
-	if track.ExternalURLs["spotify"] != "" {
-		fmt.Printf("🔗 Spotify: %s\n", track.ExternalURLs["spotify"])
+	if np.URL != "" {
+		fmt.Printf("🔗 Spotify: %s\n", np.URL)
 	}
 
 	// Extended info (audio features)
@@ -174,11 +183,8 @@ func runNow(cmd *cobra.Command, args []string) error {
 		fmt.Println("🎛️  Audio Features")
 		fmt.Println("═══════════════════")
 
-		features, err := client.GetAudioFeatures(ctx, track.ID)
-		if err == nil && len(features) > 0 && features[0] != nil {
-			feature := features[0]
-
-			fmt.Printf("🎵 Key: %s\n", getMusicalKey(int(feature.Key)))
+		if feature := np.Features; feature != nil {
+			fmt.Printf("🎵 Key: %s\n", feature.Key)
 			fmt.Printf("🎶 Tempo: %.0f BPM\n", feature.Tempo)
 			fmt.Printf("⚡ Energy: %.1f/1.0\n", feature.Energy)
 			fmt.Printf("💃 Danceability: %.1f/1.0\n", feature.Danceability)
@@ -201,7 +207,8 @@ func runNow(cmd *cobra.Command, args []string) error {
 	fmt.Println("💡 Tips:")
 	fmt.Println("   • Use --extended (-e) for audio feature analysis")
 	fmt.Println("   • Find similar music: moodify search <artist or genre>")
-	if !currently.Playing {
+	fmt.Println("   • Status bar output: --format '{{.Artist}} – {{.Track}}'")
+	if !np.Playing {
 		fmt.Println("   • Resume playback in your Spotify app")
 	}
 
@@ -214,10 +221,23 @@ func formatPlaybackDuration(d time.Duration) string {
 	return fmt.Sprintf("%d:%02d", minutes, seconds)
 }
 
-func getMusicalKey(key int) string {
-	keys := []string{"C", "C#/Db", "D", "D#/Eb", "E", "F", "F#/Gb", "G", "G#/Ab", "A", "A#/Bb", "B"}
-	if key >= 0 && key < len(keys) {
-		return keys[key]
+// formatRuntime renders a playlist length as "1h 05m 12s" or "44m 52s"
+func formatRuntime(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh %02dm %02ds", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dm %02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// renderProgressBar draws a bar of the given width filled to percentage
+func renderProgressBar(percentage float64, width int) string {
+	filled := int(percentage / 100 * float64(width))
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
 	}
-	return "Unknown"
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lorrehuggan/moodify/internal/cache"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+)
+
+// nowCacheName is the cache entry used to serve frequent `now --format` calls
+const nowCacheName = "now"
+
+// nowCacheEntry is the cached result of a fetch; Item is nil when nothing played
+type nowCacheEntry struct {
+	Item        *moodify.NowPlaying `json:"item"`
+	HasFeatures bool                `json:"has_features"`
+}
+
+// formatNeedsFeatures reports whether a --format template uses audio features
+func formatNeedsFeatures(format string) bool {
+	return strings.Contains(format, ".Features")
+}
+
+// loadNowPlayingCache returns a recent snapshot, advancing its progress by the
+// time elapsed since it was fetched
+func loadNowPlayingCache(needFeatures bool) (*moodify.NowPlaying, bool) {
+	if nowCacheTTL <= 0 {
+		return nil, false
+	}
+
+	var entry nowCacheEntry
+	savedAt, ok := cache.Load(nowCacheName, nowCacheTTL, &entry)
+	if !ok || (needFeatures && !entry.HasFeatures) {
+		return nil, false
+	}
+
+	if entry.Item != nil {
+		entry.Item.Advance(time.Since(savedAt))
+	}
+	return entry.Item, true
+}
+
+// saveNowPlayingCache stores a snapshot for subsequent --format calls
+func saveNowPlayingCache(np *moodify.NowPlaying) {
+	if nowCacheTTL <= 0 {
+		return
+	}
+	hasFeatures := np == nil || np.Features != nil
+	_ = cache.Save(nowCacheName, nowCacheEntry{Item: np, HasFeatures: hasFeatures})
+}
+
+// printNowFormat renders the --format template; nothing playing prints an empty line
+func printNowFormat(np *moodify.NowPlaying) error {
+	line, err := renderNowFormat(nowFormat, np)
+	if err != nil {
+		return err
+	}
+	fmt.Println(line)
+	return nil
+}
+
+// renderNowFormat executes a --format template against np
+func renderNowFormat(format string, np *moodify.NowPlaying) (string, error) {
+	tmpl, err := template.New("format").Funcs(nowTemplateFuncs).Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("invalid --format template: %w", err)
+	}
+
+	if np == nil {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, np); err != nil {
+		return "", fmt.Errorf("failed to render --format template: %w", err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// nowTemplateFuncs are the helpers available inside --format templates
+var nowTemplateFuncs = template.FuncMap{
+	"truncate": truncateText,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"join": func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	},
+	"bar": func(width int, percent float64) string {
+		return renderProgressBar(percent, width)
+	},
+}
+
+// truncateText shortens s to at most max characters, ending with an ellipsis
+func truncateText(max int, s string) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	if max == 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// ANSI escape sequences used to redraw the watch display in place
+const (
+	ansiClearLine  = "\x1b[2K"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+)
+
+// nowEvent is printed as ndjson and passed to --on-change hooks when the track changes
+type nowEvent struct {
+	Event      string    `json:"event"` // "track_changed" or "stopped"
+	Time       time.Time `json:"time"`
+	Playing    bool      `json:"playing"`
+	TrackID    string    `json:"track_id,omitempty"`
+	Track      string    `json:"track,omitempty"`
+	Artists    []string  `json:"artists,omitempty"`
+	Album      string    `json:"album,omitempty"`
+	URI        string    `json:"uri,omitempty"`
+	ProgressMs int       `json:"progress_ms"`
+	DurationMs int       `json:"duration_ms"`
+}
+
+// runNowWatch polls the player until interrupted, redrawing the display and
+// firing change events whenever the track changes
+func runNowWatch(ctx context.Context, client *spotify.Client) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	live := !emitNDJSON && nowFormat == "" && isTerminal(os.Stdout)
+	if live {
+		fmt.Print(ansiHideCursor)
+		defer fmt.Print(ansiShowCursor)
+	}
+
+	var lastTrackID spotify.ID
+	drawnLines := 0
+	started := false
+
+	// Templates using audio features get them once per track
+	needFeatures := nowFormat != "" && formatNeedsFeatures(nowFormat)
+	svc := newService(client)
+	var features *moodify.NowPlayingFeatures
+
+	opts := spotifyx.WatchOptions{
+		Interval:    watchInterval,
+		MaxInterval: 30 * time.Second,
+		FullState:   nowFormat != "", // templates may use device fields
+		OnError: func(err error, retryIn time.Duration) {
+			fmt.Fprintf(os.Stderr, "⚠️  Failed to get playback state: %v (retrying in %s)\n", err, retryIn)
+			drawnLines = 0 // the warning pushed the display down, start a fresh block
+		},
+	}
+
+	err := spotifyx.Watch(ctx, client, opts, func(state *spotify.PlayerState) {
+		var current *spotify.CurrentlyPlaying
+		var trackID spotify.ID
+		if state != nil {
+			current = &state.CurrentlyPlaying
+			trackID = current.Item.ID
+		}
+
+		changed := !started || trackID != lastTrackID
+		started = true
+		lastTrackID = trackID
+
+		if changed && needFeatures {
+			features = nil
+			if trackID != "" {
+				var err error
+				if features, err = svc.TrackFeatures(ctx, trackID); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Failed to get audio features: %v\n", err)
+				}
+			}
+		}
+
+		if changed {
+			event := buildNowEvent(current)
+			if emitNDJSON {
+				printNDJSON(event)
+			}
+			if onChangeCommand != "" {
+				runOnChangeHook(ctx, onChangeCommand, event)
+			}
+		}
+
+		if emitNDJSON {
+			return
+		}
+
+		if nowFormat != "" {
+			// One formatted line per poll, for status bars that tail our output
+			var np *moodify.NowPlaying
+			if state != nil {
+				np = moodify.NewNowPlaying(current, state)
+				np.Features = features
+			}
+			if err := printNowFormat(np); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			return
+		}
+
+		if live {
+			drawnLines = redrawLines(renderWatchLines(current), drawnLines)
+		} else if changed {
+			// Without a terminal we can't redraw, so only print changes
+			fmt.Println(strings.Join(renderWatchLines(current), "\n"))
+		}
+	})
+
+	if live {
+		fmt.Println()
+		fmt.Println("👋 Stopped watching")
+	}
+
+	return err
+}
+
+// renderWatchLines builds the compact live display for the current track
+func renderWatchLines(current *spotify.CurrentlyPlaying) []string {
+	header := fmt.Sprintf("🎵 Now Playing  (every %s, Ctrl-C to stop)", watchInterval)
+	if current == nil {
+		return []string{header, "", "🔇 Nothing is currently playing", "", ""}
+	}
+
+	track := current.Item
+	artists := make([]string, 0, len(track.Artists))
+	for _, artist := range track.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	playState := "⏸️ "
+	if current.Playing {
+		playState = "▶️ "
+	}
+
+	progressLine := ""
+	if track.Duration > 0 {
+		progress := time.Duration(current.Progress) * time.Millisecond
+		duration := time.Duration(track.Duration) * time.Millisecond
+		percentage := float64(current.Progress) / float64(track.Duration) * 100
+		progressLine = fmt.Sprintf("%s %s %s %s", playState,
+			formatPlaybackDuration(progress),
+			renderProgressBar(percentage, 30),
+			formatPlaybackDuration(duration))
+	}
+
+	return []string{
+		header,
+		"",
+		fmt.Sprintf("🎤 %s — %s", track.Name, strings.Join(artists, ", ")),
+		fmt.Sprintf("💿 %s", track.Album.Name),
+		progressLine,
+	}
+}
+
+// redrawLines overwrites the previously drawn block of lines and returns the
+// number of lines now on screen
+func redrawLines(lines []string, previous int) int {
+	var b strings.Builder
+	if previous > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", previous)
+	}
+	for _, line := range lines {
+		b.WriteString("\r" + ansiClearLine + line + "\n")
+	}
+	fmt.Print(b.String())
+	return len(lines)
+}
+
+// buildNowEvent converts a player snapshot into a change event
+func buildNowEvent(current *spotify.CurrentlyPlaying) nowEvent {
+	event := nowEvent{Event: "stopped", Time: time.Now().UTC()}
+	if current == nil {
+		return event
+	}
+
+	track := current.Item
+	event.Event = "track_changed"
+	event.Playing = current.Playing
+	event.TrackID = string(track.ID)
+	event.Track = track.Name
+	event.Album = track.Album.Name
+	event.URI = string(track.URI)
+	event.ProgressMs = int(current.Progress)
+	event.DurationMs = int(track.Duration)
+	for _, artist := range track.Artists {
+		event.Artists = append(event.Artists, artist.Name)
+	}
+	return event
+}
+
+// printNDJSON writes the event as a single JSON line
+func printNDJSON(event nowEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to encode event: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// runOnChangeHook runs the user's --on-change command through the shell. The
+// event is passed as JSON on stdin and as MOODIFY_* environment variables.
+func runOnChangeHook(ctx context.Context, command string, event nowEvent) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var hook *exec.Cmd
+	if runtime.GOOS == "windows" {
+		hook = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		hook = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	data, _ := json.Marshal(event)
+	hook.Stdin = strings.NewReader(string(data) + "\n")
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+	hook.Env = append(os.Environ(),
+		"MOODIFY_EVENT="+event.Event,
+		"MOODIFY_TRACK="+event.Track,
+		"MOODIFY_ARTIST="+strings.Join(event.Artists, ", "),
+		"MOODIFY_ALBUM="+event.Album,
+		"MOODIFY_URI="+event.URI,
+		fmt.Sprintf("MOODIFY_PLAYING=%t", event.Playing),
+	)
+
+	if err := hook.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  --on-change command failed: %v\n", err)
+	}
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
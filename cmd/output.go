@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats accepted by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var outputFormat string
+
+// addOutputFlag registers --output on commands that support machine-readable output
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+}
+
+// validateOutputFormat rejects unknown --output values
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputText, outputJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (use text or json)", outputFormat)
+}
+
+// jsonOutput reports whether the user asked for JSON output
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// applyPlaybackFlags queues or plays tracks depending on the --queue/--play flags
+func applyPlaybackFlags(ctx context.Context, svc *moodify.Service, tracks []spotify.SimpleTrack, queue, play bool) {
+	if len(tracks) == 0 || (!queue && !play) {
+		return
+	}
+
+	if play {
+		fmt.Printf("\n▶️  Starting playback of %d tracks...\n", len(tracks))
+		if err := svc.Play(ctx, tracks); err != nil {
+			fmt.Printf("❌ Failed to start playback: %s\n", errs.WithHint(errs.ClassifyPlayback(err)))
+			return
+		}
+		fmt.Println("✅ Now playing your results!")
+		return
+	}
+
+	fmt.Printf("\n➕ Adding %d tracks to your queue...\n", len(tracks))
+	queued, err := svc.Queue(ctx, tracks)
+	if err != nil {
+		fmt.Printf("❌ Queued %d of %d tracks: %s\n", queued, len(tracks), errs.WithHint(errs.ClassifyPlayback(err)))
+		return
+	}
+	fmt.Printf("✅ Added %d tracks to your queue! See it with: moodify queue\n", queued)
+}
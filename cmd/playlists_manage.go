@@ -0,0 +1,578 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Spotify scopes used by the playlist subcommands
+const (
+	scopePlaylistReadPrivate   = "playlist-read-private"
+	scopePlaylistModifyPrivate = "playlist-modify-private"
+	scopePlaylistModifyPublic  = "playlist-modify-public"
+)
+
+var (
+	showPlaylistLimit   int
+	showPlaylistOffset  int
+	createDescription   string
+	createPublic        bool
+	createCollaborative bool
+	addTrackCount       int
+	reorderLength       int
+	deleteConfirmed     bool
+	clonePublic         bool
+
+	// Scopes per kind of playlist action, shown in each command's help
+	playlistReadScopes   = []string{scopePlaylistReadPrivate}
+	playlistModifyScopes = []string{scopePlaylistReadPrivate, scopePlaylistModifyPrivate, scopePlaylistModifyPublic}
+	playlistCreateScopes = append([]string{"user-read-private"}, playlistModifyScopes...)
+)
+
+// addPlaylistSubcommands registers the playlist management commands under `moodify playlists`
+func addPlaylistSubcommands(playlistsCmd *cobra.Command) {
+	showCmd := withScopes(&cobra.Command{
+		Use:   "show <playlist>",
+		Short: "List the tracks in a playlist",
+		Long: `List the tracks in a playlist, one page at a time.
+
+The playlist can be a name, ID, spotify:playlist: URI or open.spotify.com link.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPlaylistShow,
+	}, playlistReadScopes...)
+	showCmd.Flags().IntVarP(&showPlaylistLimit, "limit", "n", 50, "Number of tracks per page (max 100)")
+	showCmd.Flags().IntVar(&showPlaylistOffset, "offset", 0, "Position of the first track to show")
+
+	createCmd := withScopes(&cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an empty playlist",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPlaylistCreate,
+	}, playlistCreateScopes...)
+	createCmd.Flags().StringVarP(&createDescription, "description", "d", "", "Playlist description")
+	createCmd.Flags().BoolVar(&createPublic, "public", false, "Make the playlist public")
+	createCmd.Flags().BoolVar(&createCollaborative, "collaborative", false, "Let others edit the playlist (must be private)")
+	createCmd.MarkFlagsMutuallyExclusive("public", "collaborative")
+
+	renameCmd := withScopes(&cobra.Command{
+		Use:   "rename <playlist> <new name>",
+		Short: "Rename a playlist",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPlaylistRename,
+	}, playlistModifyScopes...)
+
+	describeCmd := withScopes(&cobra.Command{
+		Use:   "describe <playlist> <description>",
+		Short: "Change a playlist's description",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPlaylistDescribe,
+	}, playlistModifyScopes...)
+
+	setPublicCmd := withScopes(&cobra.Command{
+		Use:   "set-public <playlist>",
+		Short: "Make a playlist public",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlaylistSetAccess(cmd, args[0], true)
+		},
+	}, playlistModifyScopes...)
+
+	setPrivateCmd := withScopes(&cobra.Command{
+		Use:   "set-private <playlist>",
+		Short: "Make a playlist private",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlaylistSetAccess(cmd, args[0], false)
+		},
+	}, playlistModifyScopes...)
+
+	addCmd := withScopes(&cobra.Command{
+		Use:   "add <playlist> <track uris...|search query>",
+		Short: "Add tracks to a playlist",
+		Long: `Add tracks to a playlist, either by track URI/link or by search query.
+
+If every argument after the playlist is a track URI, link or ID those tracks are
+added. Otherwise the arguments are treated as a search query and the top --count
+results are added.
+
+Examples:
+  moodify playlists add "Road Trip" spotify:track:4uLU6hMCjMI75M1A2tKUQC
+  moodify playlists add "Road Trip" upbeat 80s synthpop --count 5`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: runPlaylistAdd,
+	}, playlistModifyScopes...)
+	addCmd.Flags().IntVarP(&addTrackCount, "count", "c", 10, "Number of search results to add")
+
+	removeCmd := withScopes(&cobra.Command{
+		Use:   "remove <playlist> <track uris...|positions...>",
+		Short: "Remove tracks from a playlist",
+		Long: `Remove tracks from a playlist by track URI/link/ID or by position.
+
+Positions are 1-based, as shown by 'moodify playlists show'. Removing by URI
+removes every occurrence of the track.
+
+Examples:
+  moodify playlists remove "Road Trip" 3 7
+  moodify playlists remove "Road Trip" spotify:track:4uLU6hMCjMI75M1A2tKUQC`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: runPlaylistRemove,
+	}, playlistModifyScopes...)
+
+	reorderCmd := withScopes(&cobra.Command{
+		Use:   "reorder <playlist> <from> <to>",
+		Short: "Move tracks within a playlist",
+		Long: `Move the track at position <from> so that it ends up at position <to>.
+
+Positions are 1-based. Use --length to move a block of consecutive tracks.
+
+Examples:
+  moodify playlists reorder "Road Trip" 12 1
+  moodify playlists reorder "Road Trip" 1 10 --length 3`,
+		Args: cobra.ExactArgs(3),
+		RunE: runPlaylistReorder,
+	}, playlistModifyScopes...)
+	reorderCmd.Flags().IntVar(&reorderLength, "length", 1, "Number of consecutive tracks to move")
+
+	deleteCmd := withScopes(&cobra.Command{
+		Use:   "delete <playlist>",
+		Short: "Delete (unfollow) a playlist",
+		Long: `Delete a playlist you own, or unfollow one you follow.
+
+Spotify doesn't really delete playlists: they are removed from your library and
+can be restored from your account page on spotify.com.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPlaylistDelete,
+	}, playlistModifyScopes...)
+	deleteCmd.Flags().BoolVarP(&deleteConfirmed, "yes", "y", false, "Don't ask for confirmation")
+
+	cloneCmd := withScopes(&cobra.Command{
+		Use:   "clone <playlist> [new name]",
+		Short: "Copy a playlist into a new playlist you own",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  runPlaylistClone,
+	}, playlistCreateScopes...)
+	cloneCmd.Flags().BoolVar(&clonePublic, "public", false, "Make the copy public")
+
+	playlistsCmd.AddCommand(showCmd, createCmd, renameCmd, describeCmd, setPublicCmd,
+		setPrivateCmd, addCmd, removeCmd, reorderCmd, deleteCmd, cloneCmd)
+}
+
+// resolvePlaylistArg resolves a playlist argument for a subcommand
+func resolvePlaylistArg(ctx context.Context, cmd *cobra.Command, ref string) (*spotify.Client, *spotify.SimplePlaylist, error) {
+	client := spotifyClient(cmd)
+	playlist, err := spotifyx.ResolvePlaylist(ctx, client, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, playlist, nil
+}
+
+func runPlaylistShow(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	if showPlaylistLimit < 1 || showPlaylistLimit > 100 {
+		showPlaylistLimit = 50
+	}
+	if showPlaylistOffset < 0 {
+		showPlaylistOffset = 0
+	}
+
+	page, err := client.GetPlaylistItems(ctx, playlist.ID,
+		spotify.Limit(showPlaylistLimit), spotify.Offset(showPlaylistOffset))
+	if err != nil {
+		return fmt.Errorf("failed to get playlist tracks: %w", err)
+	}
+
+	visibility := "🔒 Private"
+	if playlist.IsPublic {
+		visibility = "🌍 Public"
+	}
+
+	fmt.Printf("🎵 %s\n", playlist.Name)
+	fmt.Printf("   %s • %s • %d tracks\n", visibility, playlist.Owner.DisplayName, page.Total)
+	if playlist.ExternalURLs["spotify"] != "" {
+		fmt.Printf("   🔗 %s\n", playlist.ExternalURLs["spotify"])
+	}
+	fmt.Println()
+
+	if len(page.Items) == 0 {
+		fmt.Println("📭 No tracks on this page")
+		return nil
+	}
+
+	for i, item := range page.Items {
+		position := showPlaylistOffset + i + 1
+		switch {
+		case item.Track.Track != nil:
+			track := item.Track.Track
+			duration := time.Duration(track.Duration) * time.Millisecond
+			fmt.Printf("%3d. %s  (%s)\n", position, formatTrackLabel(track.SimpleTrack), formatPlaybackDuration(duration))
+		case item.Track.Episode != nil:
+			fmt.Printf("%3d. 🎙️  %s\n", position, item.Track.Episode.Name)
+		default:
+			fmt.Printf("%3d. (unavailable)\n", position)
+		}
+	}
+
+	fmt.Println()
+	last := showPlaylistOffset + len(page.Items)
+	fmt.Printf("📊 Showing %d-%d of %d tracks\n", showPlaylistOffset+1, last, page.Total)
+	if last < int(page.Total) {
+		fmt.Printf("   Next page: moodify playlists show %q --offset %d\n", args[0], last)
+	}
+
+	return nil
+}
+
+func runPlaylistCreate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client := spotifyClient(cmd)
+
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	playlist, err := client.CreatePlaylistForUser(ctx, user.ID, args[0], createDescription, createPublic, createCollaborative)
+	if err != nil {
+		return fmt.Errorf("failed to create playlist: %w", err)
+	}
+
+	fmt.Printf("✅ Created playlist: %s\n", playlist.Name)
+	if playlist.ExternalURLs["spotify"] != "" {
+		fmt.Printf("   🔗 %s\n", playlist.ExternalURLs["spotify"])
+	}
+	fmt.Printf("   Add tracks: moodify playlists add %q <search query>\n", playlist.Name)
+	return nil
+}
+
+func runPlaylistRename(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := client.ChangePlaylistName(ctx, playlist.ID, args[1]); err != nil {
+		return fmt.Errorf("failed to rename playlist: %w", err)
+	}
+
+	fmt.Printf("✅ Renamed \"%s\" to \"%s\"\n", playlist.Name, args[1])
+	return nil
+}
+
+func runPlaylistDescribe(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := client.ChangePlaylistDescription(ctx, playlist.ID, args[1]); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+
+	fmt.Printf("✅ Updated description of \"%s\"\n", playlist.Name)
+	return nil
+}
+
+func runPlaylistSetAccess(cmd *cobra.Command, ref string, public bool) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, ref)
+	if err != nil {
+		return err
+	}
+
+	if err := client.ChangePlaylistAccess(ctx, playlist.ID, public); err != nil {
+		return fmt.Errorf("failed to change playlist visibility: %w", err)
+	}
+
+	if public {
+		fmt.Printf("🌍 \"%s\" is now public\n", playlist.Name)
+	} else {
+		fmt.Printf("🔒 \"%s\" is now private\n", playlist.Name)
+	}
+	return nil
+}
+
+func runPlaylistAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	ids, ok := parseTrackIDs(args[1:])
+	if !ok {
+		query := strings.Join(args[1:], " ")
+		if addTrackCount < 1 || addTrackCount > 50 {
+			addTrackCount = 10
+		}
+
+		results, err := client.Search(ctx, query, spotify.SearchTypeTrack, spotify.Limit(addTrackCount))
+		if err != nil {
+			return fmt.Errorf("search failed: %w", err)
+		}
+		if results.Tracks == nil || len(results.Tracks.Tracks) == 0 {
+			fmt.Printf("📭 No tracks found for \"%s\"\n", query)
+			return nil
+		}
+
+		fmt.Printf("🔍 Adding top %d results for \"%s\":\n", len(results.Tracks.Tracks), query)
+		for _, track := range results.Tracks.Tracks {
+			fmt.Printf("   • %s\n", formatTrackLabel(track.SimpleTrack))
+			ids = append(ids, track.ID)
+		}
+	}
+
+	if err := spotifyx.AddTracksInBatches(ctx, client, playlist.ID, ids); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Added %d tracks to \"%s\"\n", len(ids), playlist.Name)
+	return nil
+}
+
+func runPlaylistRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	var toRemove []spotify.TrackToRemove
+	removed := len(args) - 1
+	if ids, ok := parseTrackIDs(args[1:]); ok {
+		// Removing by URI removes every copy, so count them first
+		tracks, err := spotifyx.PlaylistTracks(ctx, client, playlist.ID)
+		if err != nil {
+			return err
+		}
+		wanted := map[spotify.ID]bool{}
+		for _, id := range ids {
+			wanted[id] = true
+		}
+		removed = 0
+		for _, track := range tracks {
+			if wanted[track.ID] {
+				removed++
+			}
+		}
+		if removed == 0 {
+			return fmt.Errorf("none of those tracks are in \"%s\"", playlist.Name)
+		}
+
+		for _, id := range ids {
+			toRemove = append(toRemove, spotify.TrackToRemove{URI: "spotify:track:" + string(id)})
+		}
+	} else {
+		// Look up the URI at each position; Spotify checks both against the snapshot
+		byURI := map[string][]int{}
+		var order []string
+		for _, arg := range args[1:] {
+			position, err := strconv.Atoi(arg)
+			if err != nil || position < 1 {
+				return fmt.Errorf("%q is neither a track URI nor a position", arg)
+			}
+
+			page, err := client.GetPlaylistItems(ctx, playlist.ID, spotify.Limit(1), spotify.Offset(position-1))
+			if err != nil {
+				return fmt.Errorf("failed to get playlist tracks: %w", err)
+			}
+			if len(page.Items) == 0 {
+				return fmt.Errorf("position %d is past the end of the playlist (%d tracks)", position, page.Total)
+			}
+
+			uri := playlistItemURI(page.Items[0])
+			if uri == "" {
+				return fmt.Errorf("track at position %d can't be removed", position)
+			}
+			if _, seen := byURI[uri]; !seen {
+				order = append(order, uri)
+			}
+			byURI[uri] = append(byURI[uri], position-1)
+		}
+
+		for _, uri := range order {
+			toRemove = append(toRemove, spotify.TrackToRemove{URI: uri, Positions: byURI[uri]})
+		}
+	}
+
+	if _, err := client.RemoveTracksFromPlaylistOpt(ctx, playlist.ID, toRemove, playlist.SnapshotID); err != nil {
+		return fmt.Errorf("failed to remove tracks: %w", err)
+	}
+
+	fmt.Printf("🗑️  Removed %d tracks from \"%s\"\n", removed, playlist.Name)
+	return nil
+}
+
+func runPlaylistReorder(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	from, err := strconv.Atoi(args[1])
+	if err != nil || from < 1 {
+		return fmt.Errorf("invalid <from> position %q", args[1])
+	}
+	to, err := strconv.Atoi(args[2])
+	if err != nil || to < 1 {
+		return fmt.Errorf("invalid <to> position %q", args[2])
+	}
+	if reorderLength < 1 {
+		return fmt.Errorf("--length must be at least 1")
+	}
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	total := int(playlist.Tracks.Total)
+	if from+reorderLength-1 > total || to+reorderLength-1 > total {
+		return fmt.Errorf("positions are past the end of the playlist (%d tracks)", total)
+	}
+	if from == to {
+		fmt.Println("👌 Nothing to move")
+		return nil
+	}
+
+	// Spotify inserts before a position in the original order, so moving a
+	// block down has to skip over the block itself
+	insertBefore := to - 1
+	if to > from {
+		insertBefore = to - 1 + reorderLength
+	}
+
+	_, err = client.ReorderPlaylistTracks(ctx, playlist.ID, spotify.PlaylistReorderOptions{
+		RangeStart:   spotify.Numeric(from - 1),
+		RangeLength:  spotify.Numeric(reorderLength),
+		InsertBefore: spotify.Numeric(insertBefore),
+		SnapshotID:   playlist.SnapshotID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reorder playlist: %w", err)
+	}
+
+	if reorderLength == 1 {
+		fmt.Printf("✅ Moved track %d to position %d in \"%s\"\n", from, to, playlist.Name)
+	} else {
+		fmt.Printf("✅ Moved tracks %d-%d to position %d in \"%s\"\n", from, from+reorderLength-1, to, playlist.Name)
+	}
+	return nil
+}
+
+func runPlaylistDelete(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !deleteConfirmed {
+		question := fmt.Sprintf("🗑️  Delete \"%s\" (%d tracks)?", playlist.Name, playlist.Tracks.Total)
+		if !askYesNo(question) {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	if err := client.UnfollowPlaylist(ctx, playlist.ID); err != nil {
+		return fmt.Errorf("failed to delete playlist: %w", err)
+	}
+
+	fmt.Printf("✅ Deleted \"%s\"\n", playlist.Name)
+	return nil
+}
+
+func runPlaylistClone(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	name := playlist.Name + " (copy)"
+	if len(args) > 1 {
+		name = args[1]
+	}
+
+	tracks, err := spotifyx.PlaylistTracks(ctx, client, playlist.ID)
+	if err != nil {
+		return err
+	}
+
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	description := playlist.Description
+	if description == "" {
+		description = fmt.Sprintf("Copy of %s", playlist.Name)
+	}
+
+	clone, err := client.CreatePlaylistForUser(ctx, user.ID, name, description, clonePublic, false)
+	if err != nil {
+		return fmt.Errorf("failed to create playlist: %w", err)
+	}
+
+	ids := make([]spotify.ID, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.ID)
+	}
+	if err := spotifyx.AddTracksInBatches(ctx, client, clone.ID, ids); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Cloned \"%s\" into \"%s\" (%d tracks)\n", playlist.Name, clone.Name, len(ids))
+	if skipped := int(playlist.Tracks.Total) - len(ids); skipped > 0 {
+		fmt.Printf("   ⚠️  Skipped %d local files or episodes\n", skipped)
+	}
+	if clone.ExternalURLs["spotify"] != "" {
+		fmt.Printf("   🔗 %s\n", clone.ExternalURLs["spotify"])
+	}
+	return nil
+}
+
+// parseTrackIDs returns the track IDs if every argument is a track URI, link or ID
+func parseTrackIDs(args []string) ([]spotify.ID, bool) {
+	ids := make([]spotify.ID, 0, len(args))
+	for _, arg := range args {
+		id, ok := spotifyx.ParseID(arg, "track")
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// playlistItemURI returns the URI of a playlist item's track or episode
+func playlistItemURI(item spotify.PlaylistItem) string {
+	switch {
+	case item.Track.Track != nil:
+		return string(item.Track.Track.URI)
+	case item.Track.Episode != nil:
+		return string(item.Track.Episode.URI)
+	}
+	return ""
+}
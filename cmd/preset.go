@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/history"
+	"github.com/lorrehuggan/moodify/internal/preset"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	presetFromQuery   string
+	presetFromSearch  string
+	presetDescription string
+)
+
+func init() {
+	presetCmd := &cobra.Command{
+		Use:   "preset",
+		Short: "Save and manage mood presets",
+		Long: `Presets are moods saved under a name: the filters a vibe was parsed into and
+the seeds it was matched with. Use one with --preset on search, discover and
+radio, or as a discover --mood.
+
+moodify ships with happy, sad, energetic, chill, angry and romantic. They can be
+overwritten with 'moodify preset save' or deleted like any other preset, and the
+presets file (~/.config/moodify/presets.json) can be edited by hand.
+
+Examples:
+  moodify preset save focus --from-query "deep instrumental ambient"
+  moodify preset save gym --from-search 12
+  moodify search --preset focus --duration 45m
+  moodify discover --preset focus --decade 90s
+  moodify radio --preset focus`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List presets",
+		Args:  cobra.NoArgs,
+		RunE:  runPresetList,
+	}
+	addOutputFlag(listCmd)
+
+	showCmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a preset's filters and seeds",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPresetShow,
+	}
+	addOutputFlag(showCmd)
+
+	saveCmd := withScopes(&cobra.Command{
+		Use:   "save <name>",
+		Short: "Save a vibe or a past search as a preset",
+		Long: `Save a preset from a vibe, which is parsed (with AI when OPENAI_API_KEY is set)
+and matched with seeds once, or from a search in 'moodify history searches'.
+
+Saving over an existing preset keeps its aliases and, unless --description is
+given, its description.
+
+Examples:
+  moodify preset save focus --from-query "deep instrumental ambient"
+  moodify preset save happy --from-query "sunny indie pop" --description "My kind of happy"
+  moodify preset save gym --from-search last`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPresetSave,
+	}, "user-top-read", "user-read-private")
+	saveCmd.Flags().StringVar(&presetFromQuery, "from-query", "", "Vibe to parse into the preset")
+	saveCmd.Flags().StringVar(&presetFromSearch, "from-search", "", "ID of a recorded search to copy the filters and seeds of, or last")
+	saveCmd.Flags().StringVar(&presetDescription, "description", "", "Short description shown in 'moodify preset list'")
+	saveCmd.MarkFlagsOneRequired("from-query", "from-search")
+	saveCmd.MarkFlagsMutuallyExclusive("from-query", "from-search")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a preset",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPresetDelete,
+	}
+
+	presetCmd.AddCommand(listCmd, showCmd, saveCmd, deleteCmd)
+	rootCmd.AddCommand(presetCmd)
+}
+
+func runPresetList(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	store, err := preset.Open()
+	if err != nil {
+		return err
+	}
+	presets, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if presets == nil {
+			presets = []preset.Preset{}
+		}
+		return printJSON(presets)
+	}
+
+	fmt.Println("🎚️  Mood Presets")
+	fmt.Println("═══════════════")
+	fmt.Println()
+
+	if len(presets) == 0 {
+		fmt.Println("📭 No presets")
+		fmt.Println()
+		fmt.Println("💡 Save one: moodify preset save focus --from-query \"deep instrumental ambient\"")
+		return nil
+	}
+
+	for _, p := range presets {
+		fmt.Printf("   %-12s %s\n", p.Name, describePreset(&p))
+		if summary := filterSummary(p.Filters); len(summary) > 0 {
+			fmt.Printf("   %-12s %s\n", "", strings.Join(summary, " · "))
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("💡 Use one: moodify search --preset <name>, moodify discover --mood <name>, moodify radio --preset <name>")
+	fmt.Printf("   Edit them with 'moodify preset save' or in %s\n", store.Path())
+	return nil
+}
+
+func runPresetShow(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	p, err := moodify.LoadPreset(args[0])
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return printJSON(p)
+	}
+
+	fmt.Printf("🎚️  %s: %s\n", p.Name, describePreset(p))
+	if len(p.Aliases) > 0 {
+		fmt.Printf("   Also answers to: %s\n", strings.Join(p.Aliases, ", "))
+	}
+	if p.Query != "" {
+		fmt.Printf("   Made from: %q\n", p.Query)
+	}
+	if p.CreatedAt != nil {
+		fmt.Printf("   Saved: %s\n", p.CreatedAt.Local().Format("Mon Jan 2 2006 15:04"))
+	}
+
+	fmt.Println()
+	fmt.Println("🎼 Filters:")
+	summary := filterSummary(p.Filters)
+	if len(summary) == 0 {
+		fmt.Println("   (none)")
+	}
+	for _, part := range summary {
+		fmt.Printf("   %s\n", part)
+	}
+
+	fmt.Println()
+	fmt.Println("🌱 Seeds:")
+	if p.Seeds.Empty() {
+		fmt.Println("   (none, built from the filters and your taste each time)")
+	}
+	if len(p.Seeds.Genres) > 0 {
+		fmt.Printf("   Genres: %s\n", strings.Join(p.Seeds.Genres, ", "))
+	}
+	if len(p.Seeds.Artists) > 0 {
+		fmt.Printf("   Artists: %s\n", strings.Join(p.Seeds.Artists, ", "))
+	}
+	if len(p.Seeds.Tracks) > 0 {
+		fmt.Printf("   Tracks: %s\n", strings.Join(p.Seeds.Tracks, ", "))
+	}
+	return nil
+}
+
+func runPresetSave(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	name := strings.ToLower(args[0])
+	if err := preset.ValidateName(name); err != nil {
+		return err
+	}
+	store, err := preset.Open()
+	if err != nil {
+		return err
+	}
+
+	var p *preset.Preset
+	if presetFromSearch != "" {
+		search, err := loadSearch(presetFromSearch)
+		if err != nil {
+			return err
+		}
+		if search.Command != history.CommandSearch || search.Filters == nil {
+			return fmt.Errorf("%s #%d has no parsed filters to save; pick a search from 'moodify history searches'", search.Command, search.ID)
+		}
+		p = &preset.Preset{Name: name, Query: search.Query, Filters: *search.Filters, Seeds: search.Seeds}
+		fmt.Printf("📋 Copying search #%d: %q\n", search.ID, search.Query)
+	} else {
+		var parsed *moodify.ParseResult
+		p, parsed = newService(spotifyClient(cmd)).NewPreset(ctx, name, presetFromQuery)
+		if parsed.AIError != nil {
+			fmt.Printf("⚠️  AI parsing failed, used basic parsing instead: %v\n", parsed.AIError)
+		}
+		fmt.Printf("🎯 Parsed %q with %s parsing\n", presetFromQuery, parsed.Parser)
+	}
+
+	// Keep what the user set on the preset being replaced
+	if existing, err := store.Get(name); err == nil && existing.Name == name {
+		p.Aliases, p.Description = existing.Aliases, existing.Description
+	}
+	if presetDescription != "" {
+		p.Description = presetDescription
+	}
+	now := time.Now()
+	p.CreatedAt = &now
+
+	replaced, err := store.Save(*p)
+	if err != nil {
+		return err
+	}
+
+	verb := "Saved"
+	if replaced {
+		verb = "Updated"
+	}
+	fmt.Printf("✅ %s preset '%s'\n", verb, p.Name)
+	if summary := filterSummary(p.Filters); len(summary) > 0 {
+		fmt.Printf("   %s\n", strings.Join(summary, " · "))
+	}
+	fmt.Printf("💡 Try it: moodify search --preset %s\n", p.Name)
+	return nil
+}
+
+func runPresetDelete(cmd *cobra.Command, args []string) error {
+	store, err := preset.Open()
+	if err != nil {
+		return err
+	}
+	deleted, err := store.Delete(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🗑️  Deleted preset '%s'\n", deleted.Name)
+	return nil
+}
+
+// vibeOrPresetArgs accepts a vibe as arguments, or no arguments when the
+// --preset flag behind preset is given
+func vibeOrPresetArgs(preset *string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		switch {
+		case *preset != "" && len(args) > 0:
+			return fmt.Errorf("give either a vibe or --preset, not both")
+		case *preset == "" && len(args) == 0:
+			return fmt.Errorf("requires a vibe, e.g. moodify %s \"late night lofi\", or --preset <name>", cmd.Name())
+		}
+		return nil
+	}
+}
+
+// describePreset returns a preset's description, or the vibe it was made from
+func describePreset(p *preset.Preset) string {
+	switch {
+	case p.Description != "":
+		return p.Description
+	case p.Query != "":
+		return fmt.Sprintf("%q", p.Query)
+	}
+	return "(no description)"
+}
+
+// filterSummary describes each filter that is set, e.g. "energy ≥ 0.70"
+func filterSummary(f ai.Filters) []string {
+	var parts []string
+	if len(f.Genres) > 0 {
+		parts = append(parts, "genres "+strings.Join(f.Genres, ", "))
+	}
+	if len(f.ExcludeGenres) > 0 {
+		parts = append(parts, "no "+strings.Join(f.ExcludeGenres, ", "))
+	}
+	ranges := []struct {
+		name     string
+		min, max float64
+		format   string
+		unit     string
+	}{
+		{"energy", f.MinEnergy, f.MaxEnergy, "%.2f", ""},
+		{"valence", f.MinValence, f.MaxValence, "%.2f", ""},
+		{"danceability", f.MinDanceability, f.MaxDanceability, "%.2f", ""},
+		{"tempo", f.MinTempo, f.MaxTempo, "%.0f", " BPM"},
+		{"popularity", float64(f.MinPopularity), float64(f.MaxPopularity), "%.0f", ""},
+		{"years", float64(f.YearStart), float64(f.YearEnd), "%.0f", ""},
+	}
+	for _, r := range ranges {
+		switch {
+		case r.min > 0 && r.max > 0:
+			parts = append(parts, fmt.Sprintf("%s "+r.format+" - "+r.format+"%s", r.name, r.min, r.max, r.unit))
+		case r.min > 0:
+			parts = append(parts, fmt.Sprintf("%s ≥ "+r.format+"%s", r.name, r.min, r.unit))
+		case r.max > 0:
+			parts = append(parts, fmt.Sprintf("%s ≤ "+r.format+"%s", r.name, r.max, r.unit))
+		}
+	}
+	return parts
+}
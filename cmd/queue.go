@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+var queueLimit int
+
+func init() {
+	queueCmd := withScopes(&cobra.Command{
+		Use:   "queue",
+		Short: "Show your current Spotify playback queue",
+		Long: `Display the track that is currently playing and the tracks queued up after it.
+Add search or discovery results to the queue with --queue, or play them right away with --play.`,
+		RunE: runQueue,
+	}, playbackReadScopes...)
+
+	queueCmd.Flags().IntVarP(&queueLimit, "limit", "n", 20, "Number of queued tracks to show")
+
+	rootCmd.AddCommand(queueCmd)
+}
+
+func runQueue(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	client := spotifyClient(cmd)
+
+	queue, err := client.GetQueue(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get playback queue: %w", errs.ClassifyPlayback(err))
+	}
+
+	fmt.Println("🎶 Playback Queue")
+	fmt.Println("═════════════════")
+	fmt.Println()
+
+	if queue.CurrentlyPlaying.ID == "" && len(queue.Items) == 0 {
+		fmt.Println("📭 Your queue is empty")
+		fmt.Println()
+		fmt.Println("💡 Tips:")
+		fmt.Println("   • Start playing music in Spotify")
+		fmt.Println("   • Queue some results: moodify search <query> --queue")
+		return nil
+	}
+
+	if queue.CurrentlyPlaying.ID != "" {
+		fmt.Printf("▶️  Now: %s\n\n", formatQueueTrack(queue.CurrentlyPlaying))
+	}
+
+	if len(queue.Items) == 0 {
+		fmt.Println("📭 Nothing queued up next")
+		return nil
+	}
+
+	if queueLimit < 1 {
+		queueLimit = 20
+	}
+
+	for i, track := range queue.Items {
+		if i >= queueLimit {
+			break
+		}
+		fmt.Printf("%2d. %s\n", i+1, formatQueueTrack(track))
+	}
+
+	if len(queue.Items) > queueLimit {
+		fmt.Printf("\n   ... and %d more\n", len(queue.Items)-queueLimit)
+	}
+
+	return nil
+}
+
+// formatQueueTrack renders a queue entry as "Track — Artist (m:ss)"
+func formatQueueTrack(track spotify.FullTrack) string {
+	artist := "Unknown Artist"
+	if len(track.Artists) > 0 {
+		artist = track.Artists[0].Name
+	}
+	duration := time.Duration(track.Duration) * time.Millisecond
+	return fmt.Sprintf("%s — %s  (%s)", track.Name, artist, formatPlaybackDuration(duration))
+}
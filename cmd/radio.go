@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/config"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/lorrehuggan/moodify/internal/radio"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+// radioBanFile is the default ban list inside the config directory
+const radioBanFile = "radio-bans.txt"
+
+var (
+	radioMinQueue int
+	radioBatch    int
+	radioInterval time.Duration
+	radioBans     []string
+	radioBanPath  string
+	radioPreset   string
+)
+
+func init() {
+	radioCmd := unlimitedRequests(withScopes(&cobra.Command{
+		Use:   "radio <vibe> | --preset <name>",
+		Short: "Keep your queue topped up with music for a mood",
+		Long: `Start an endless radio for a mood. moodify watches your playback queue and,
+when fewer than --min-queue of its tracks are left, queues new recommendations for
+the mood, seeded by the tracks you just played.
+
+Nothing played this session is queued again, and neither is anything on the ban
+list. The ban list is read from ~/.config/moodify/radio-bans.txt (or --ban-file),
+one track URL, URI or ID, or artist name per line. Add more with --ban.
+
+While the radio is running, type a new vibe and press Enter to change the mood,
+or type "quit" (or press Ctrl-C) to stop.
+
+Examples:
+  moodify radio "late night lofi"
+  moodify radio "upbeat 80s synthpop" --min-queue 5 --ban "Rick Astley"
+  moodify radio --preset focus`,
+		Args: vibeOrPresetArgs(&radioPreset),
+		RunE: runRadio,
+	}, "user-read-playback-state", "user-modify-playback-state", "user-read-private", "user-top-read"))
+
+	radioCmd.Flags().IntVar(&radioMinQueue, "min-queue", 3, "Top up the queue when fewer than this many radio tracks are left")
+	radioCmd.Flags().IntVar(&radioBatch, "batch", 5, "How many tracks to add each time")
+	radioCmd.Flags().DurationVar(&radioInterval, "interval", 10*time.Second, "How often to check the queue")
+	radioCmd.Flags().StringSliceVar(&radioBans, "ban", nil, "Track or artist to never queue (repeatable)")
+	radioCmd.Flags().StringVar(&radioBanPath, "ban-file", "", "Ban list file (default ~/.config/moodify/"+radioBanFile+")")
+	radioCmd.Flags().StringVar(&market, "market", "US", "ISO market code to recommend playable tracks for (e.g., US, GB)")
+	radioCmd.Flags().StringVar(&radioPreset, "preset", "", "Play a saved preset instead of a vibe (see 'moodify preset list')")
+
+	rootCmd.AddCommand(radioCmd)
+}
+
+func runRadio(cmd *cobra.Command, args []string) error {
+	if radioMinQueue < 1 || radioBatch < 1 {
+		return fmt.Errorf("--min-queue and --batch must be at least 1")
+	}
+	if radioInterval < 2*time.Second {
+		return fmt.Errorf("--interval must be at least 2s")
+	}
+	banPath := radioBanPath
+	if banPath == "" {
+		path, err := config.Path(radioBanFile)
+		if err != nil {
+			return err
+		}
+		banPath = path
+	}
+	entries, err := radio.LoadBans(banPath)
+	if err != nil {
+		return err
+	}
+	bans := radio.ParseBans(append(entries, radioBans...))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := spotifyClient(cmd)
+	svc := newService(client)
+
+	vibe := strings.Join(args, " ")
+	var filters ai.Filters
+	var seeds *spotify.Seeds
+	if radioPreset != "" {
+		p, err := moodify.LoadPreset(radioPreset)
+		if err != nil {
+			return err
+		}
+		vibe, filters, seeds = fmt.Sprintf("%s (preset)", p.Name), p.Filters, moodify.PresetSeeds(p)
+	} else {
+		filters = moodify.Parse(ctx, vibe).Filters
+	}
+	session := radio.NewSession(bans)
+
+	fmt.Printf("📻 Radio: %s\n", vibe)
+	if bans.Len() > 0 {
+		fmt.Printf("🚫 %d banned tracks and artists\n", bans.Len())
+	}
+	fmt.Println("   Type a new vibe to change the mood, or \"quit\" to stop")
+	fmt.Println()
+
+	prompts := readPrompts(os.Stdin)
+	ticker := time.NewTicker(radioInterval)
+	defer ticker.Stop()
+
+	var playing spotify.ID
+	for {
+		wait := radioInterval
+		if err := topUpRadio(ctx, svc, session, filters, seeds, &playing); err != nil && ctx.Err() == nil {
+			// Don't check again before Spotify's rate limit is over
+			wait = max(wait, spotifyx.RetryAfter(err))
+			fmt.Fprintf(os.Stderr, "⚠️  %s (retrying in %s)\n", errs.WithHint(errs.ClassifyPlayback(err)), wait)
+		}
+		ticker.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("\n👋 Radio stopped")
+			return nil
+		case prompt, ok := <-prompts:
+			if !ok {
+				prompts = nil // stdin closed, keep playing
+				continue
+			}
+			switch strings.ToLower(prompt) {
+			case "":
+				continue
+			case "q", "quit", "exit":
+				fmt.Println("👋 Radio stopped")
+				return nil
+			}
+			vibe, filters, seeds = prompt, moodify.Parse(ctx, prompt).Filters, nil
+			session.ResetSeeds()
+			fmt.Printf("🎚️  Changing the mood to: %s\n", vibe)
+		case <-ticker.C:
+		}
+	}
+}
+
+// topUpRadio records what's playing and queues new tracks when the radio's
+// share of the queue runs low. seeds, when given, are used until there are
+// played tracks to seed from.
+func topUpRadio(ctx context.Context, svc *moodify.Service, session *radio.Session, filters ai.Filters, seeds *spotify.Seeds, playing *spotify.ID) error {
+	client := svc.Client()
+	queue, err := client.GetQueue(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get playback queue: %w", err)
+	}
+
+	current := queue.CurrentlyPlaying
+	if current.ID != "" && current.ID != *playing {
+		*playing = current.ID
+		session.Played(current.ID)
+		fmt.Printf("🎵 %s\n", formatQueueTrack(current))
+	}
+
+	upcoming := make([]spotify.ID, 0, len(queue.Items))
+	for _, item := range queue.Items {
+		upcoming = append(upcoming, item.ID)
+	}
+	if session.Pending(upcoming) >= radioMinQueue {
+		return nil
+	}
+
+	recSeeds := session.Seeds(moodify.ValidGenres(filters.Genres))
+	if len(recSeeds.Tracks) == 0 && seeds != nil {
+		recSeeds = *seeds
+	} else if len(recSeeds.Genres)+len(recSeeds.Tracks) == 0 {
+		recSeeds = svc.Seeds(ctx, filters)
+	}
+
+	recs, err := spotifyx.GetRecommendationsWithFilters(ctx, client, recSeeds,
+		filters.MinDanceability, filters.MaxDanceability,
+		filters.MinEnergy, filters.MaxEnergy,
+		filters.MinValence, filters.MaxValence,
+		filters.MinTempo, filters.MaxTempo,
+		filters.MinPopularity, filters.MaxPopularity,
+		radioBatch*4, svc.Market())
+	if err != nil {
+		return fmt.Errorf("failed to get recommendations: %w", err)
+	}
+
+	picks := session.Pick(moodify.FilterByYear(recs.Tracks, filters), radioBatch)
+	if len(picks) == 0 {
+		fmt.Println("😔 No new tracks for this mood right now. Try a broader vibe.")
+		return nil
+	}
+
+	// Start playback if nothing is on, otherwise add to the queue
+	if current.ID == "" {
+		if err := svc.Play(ctx, picks); err != nil {
+			return fmt.Errorf("failed to start playback: %w", err)
+		}
+		session.Queued(picks)
+		fmt.Printf("▶️  Started the radio with %d tracks\n", len(picks))
+		return nil
+	}
+
+	queued, err := svc.Queue(ctx, picks)
+	session.Queued(picks[:queued])
+	if queued > 0 {
+		names := make([]string, 0, queued)
+		for _, t := range picks[:queued] {
+			names = append(names, t.Name)
+		}
+		fmt.Printf("➕ Queued %d tracks: %s\n", queued, strings.Join(names, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to queue tracks: %w", err)
+	}
+	return nil
+}
+
+// readPrompts sends each line typed on r to the returned channel, closing it
+// when input ends
+func readPrompts(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- strings.TrimSpace(scanner.Text())
+		}
+	}()
+	return lines
+}
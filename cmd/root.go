@@ -1,12 +1,21 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/lorrehuggan/moodify/internal/errs"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
 	"github.com/spf13/cobra"
 )
 
+var (
+	noCache      bool
+	refreshCache bool
+	verbose      bool
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "moodify",
 	Short: "Zero-setup music discovery CLI for Spotify",
@@ -22,15 +31,72 @@ Examples:
   moodify search sad indie for rainy days    # Perfect melancholy playlist
 
 Get started in 30 seconds: no API keys, no Spotify app setup required!`,
+	// Execute reports errors itself, with hints and exit codes
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Flags parsed fine, so later failures aren't about usage
+		cmd.SilenceUsage = true
+
+		switch {
+		case noCache:
+			spotifyx.ResponseCacheMode = spotifyx.CacheOff
+		case refreshCache:
+			spotifyx.ResponseCacheMode = spotifyx.CacheRefresh
+		}
+
+		if verbose {
+			spotifyx.DefaultRetryPolicy.Logf = func(format string, args ...interface{}) {
+				fmt.Fprintf(os.Stderr, "↻ "+format+"\n", args...)
+			}
+		}
+
+		return connect(cmd)
+	},
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(reportError(err))
+	}
+}
+
+// exitError ends a command that has already reported its failure, with the
+// given exit code and nothing more printed
+type exitError struct {
+	code int
+}
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// reportError prints err with a hint on how to fix it, or as a JSON object
+// with --output json, and returns the exit code for it
+func reportError(err error) int {
+	var exit exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+
+	details := errs.Describe(err)
+	if jsonOutput() {
+		_ = printJSON(struct {
+			Error errs.Details `json:"error"`
+		}{details})
+		return details.ExitCode
+	}
+
+	fmt.Fprintf(os.Stderr, "❌ %s\n", details.Message)
+	if details.Hint != "" {
+		fmt.Fprintf(os.Stderr, "💡 %s\n", details.Hint)
 	}
+	return details.ExitCode
 }
 
 func init() {
 	// child commands added in other files' init()
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Don't read or write cached Spotify responses")
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "Ignore cached Spotify responses and cache fresh ones")
+	rootCmd.MarkFlagsMutuallyExclusive("no-cache", "refresh")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed processing information, including retried Spotify requests")
 }
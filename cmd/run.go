@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/cadence"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	// defaultRunDuration is the run length when --duration isn't given
+	defaultRunDuration = 30 * time.Minute
+	// runCandidates is how many recommendations are fetched per tempo
+	runCandidates = 100
+)
+
+var (
+	runCadence      float64
+	runRampTo       float64
+	runRampStep     float64
+	runBPMTolerance float64
+	runSaveAs       string
+	runPublic       bool
+	runQueueTracks  bool
+	runPlayTracks   bool
+)
+
+func init() {
+	runCmd := withScopes(&cobra.Command{
+		Use:   "run [vibe]",
+		Short: "Build a running playlist matched to your cadence",
+		Long: `Build a playlist whose tempo matches your running cadence in steps per minute.
+
+Tracks are accepted when their tempo matches the cadence on the beat, at half-time
+(an 85 BPM track at 170 spm) or at double-time, within --bpm-tolerance. Tempos and
+time signatures are checked against Spotify's audio features, so only 4/4 tracks
+make the cut. The playlist is filled to --duration (30m by default).
+
+Use --ramp-to to build up (or wind down) the cadence gradually over the run.
+
+Examples:
+  moodify run --cadence 170 --duration 30m
+  moodify run --cadence 165 --duration 45m upbeat indie rock
+  moodify run --cadence 160 --ramp-to 175 --duration 40m --save "Tempo Run"`,
+		RunE: runRun,
+	}, slices.Concat(playlistCreateScopes, []string{"user-top-read", "user-modify-playback-state"})...)
+
+	runCmd.Flags().Float64Var(&runCadence, "cadence", 170, "Target cadence in steps per minute")
+	runCmd.Flags().Float64Var(&runRampTo, "ramp-to", 0, "Ramp the cadence progressively to this value over the run")
+	runCmd.Flags().Float64Var(&runRampStep, "ramp-step", 5, "Cadence change between ramp stages")
+	runCmd.Flags().Float64Var(&runBPMTolerance, "bpm-tolerance", 4, "How far a track's tempo may be from the cadence")
+	addDurationFlags(runCmd)
+	runCmd.Flags().StringVar(&market, "market", "US", "ISO market code to recommend playable tracks for (e.g., US, GB)")
+	runCmd.Flags().StringVar(&runSaveAs, "save", "", "Save the run to a new playlist with this name")
+	runCmd.Flags().BoolVar(&runPublic, "public", false, "Make the saved playlist public (default: private)")
+	runCmd.Flags().BoolVar(&runQueueTracks, "queue", false, "Add the run to your playback queue")
+	runCmd.Flags().BoolVar(&runPlayTracks, "play", false, "Start playing the run immediately")
+	runCmd.MarkFlagsMutuallyExclusive("queue", "play")
+
+	rootCmd.AddCommand(runCmd)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := validateDurationFlags(); err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("duration") {
+		targetDuration = defaultRunDuration
+	}
+	if runCadence < 100 || runCadence > 230 || (runRampTo != 0 && (runRampTo < 100 || runRampTo > 230)) {
+		return fmt.Errorf("cadence should be between 100 and 230 steps per minute")
+	}
+	if runBPMTolerance <= 0 {
+		return fmt.Errorf("--bpm-tolerance must be positive")
+	}
+
+	stages, err := cadence.Ramp(runCadence, runRampTo, targetDuration, runRampStep)
+	if err != nil {
+		return err
+	}
+
+	client := spotifyClient(cmd)
+	svc := newService(client)
+
+	// The vibe only steers genre, energy and era; tempo comes from the cadence
+	vibe := strings.Join(args, " ")
+	var filters ai.Filters
+	if vibe != "" {
+		filters = moodify.Parse(ctx, vibe).Filters
+	}
+	seeds := svc.Seeds(ctx, filters)
+
+	if len(stages) > 1 {
+		fmt.Printf("🏃 Finding tracks ramping from %.0f to %.0f spm over %s...\n",
+			stages[0].Cadence, stages[len(stages)-1].Cadence, formatRuntime(targetDuration))
+	} else {
+		fmt.Printf("🏃 Finding tracks for %s at %.0f spm...\n", formatRuntime(targetDuration), runCadence)
+	}
+
+	// Fetch candidates on the beat and at half-time for every stage, remembering
+	// the tempo each was recommended for in case audio features aren't available
+	var pool []spotify.SimpleTrack
+	requested := map[spotify.ID]float64{}
+	var lastErr error
+	for _, stage := range stages {
+		for _, tempo := range []float64{stage.Cadence, stage.Cadence / 2} {
+			recs, err := spotifyx.GetRecommendationsForTempo(ctx, client, seeds,
+				tempo, runBPMTolerance*tempo/stage.Cadence,
+				filters.MinEnergy, filters.MaxEnergy, filters.MinPopularity,
+				runCandidates, svc.Market())
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			for _, t := range recs.Tracks {
+				if _, seen := requested[t.ID]; !seen {
+					requested[t.ID] = tempo
+					pool = append(pool, t)
+				}
+			}
+		}
+	}
+	if len(pool) == 0 {
+		if lastErr != nil {
+			return fmt.Errorf("failed to get recommendations: %w", lastErr)
+		}
+		fmt.Println("😔 No tracks found at that cadence. Try a wider --bpm-tolerance or a different vibe.")
+		return nil
+	}
+	pool = moodify.FilterByYear(pool, filters)
+
+	ids := make([]spotify.ID, 0, len(pool))
+	for _, t := range pool {
+		ids = append(ids, t.ID)
+	}
+	features, err := spotifyx.GetAudioFeaturesBatch(ctx, client, ids)
+	verified := err == nil && len(features) > 0
+	if !verified {
+		fmt.Println("⚠️  Audio features unavailable, so tempos and time signatures can't be verified")
+	}
+
+	candidates := make([]cadence.Candidate, 0, len(pool))
+	notFourFour := 0
+	for i, t := range pool {
+		c := cadence.Candidate{Index: i, Duration: t.TimeDuration()}
+		if f := features[t.ID]; f != nil {
+			if f.TimeSignature != 4 {
+				notFourFour++
+				continue
+			}
+			c.Tempo = float64(f.Tempo)
+		} else if !verified {
+			c.Tempo = requested[t.ID]
+		} else {
+			continue // can't check its tempo
+		}
+		candidates = append(candidates, c)
+	}
+
+	placements, err := cadence.Assemble(stages, candidates, cadence.Options{
+		Tolerance:         runBPMTolerance,
+		DurationTolerance: durationTolerance,
+		Method:            durationFit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(placements) == 0 {
+		fmt.Println("😔 No tracks matched the cadence. Try a wider --bpm-tolerance or a different vibe.")
+		return nil
+	}
+
+	tracks := make([]spotify.SimpleTrack, 0, len(placements))
+	fmt.Println()
+	for i, p := range placements {
+		if len(stages) > 1 && (i == 0 || placements[i-1].Stage != p.Stage) {
+			fmt.Printf("── %.0f spm ──\n", stages[p.Stage].Cadence)
+		}
+
+		t := pool[p.Index]
+		tracks = append(tracks, t)
+		artist := "Unknown Artist"
+		if len(t.Artists) > 0 {
+			artist = t.Artists[0].Name
+		}
+		approx := ""
+		if !verified {
+			approx = "~"
+		}
+		fmt.Printf("%2d. %s — %s\n    %s%.0f BPM (%s) • %s\n",
+			i+1, t.Name, artist, approx, p.Tempo, p.Match.Alignment, formatPlaybackDuration(t.TimeDuration()))
+	}
+
+	fmt.Println()
+	printRuntime(tracks)
+	if notFourFour > 0 {
+		fmt.Printf("   Skipped %d tracks that aren't in 4/4\n", notFourFour)
+	}
+
+	if runSaveAs != "" {
+		fmt.Printf("\n💾 Saving to playlist: %s\n", runSaveAs)
+		if _, err := svc.SavePlaylist(ctx, runSaveAs, tracks, moodify.SavePlaylistOptions{Public: runPublic}); err != nil {
+			fmt.Printf("❌ Failed to create playlist: %v\n", err)
+		} else {
+			fmt.Printf("✅ Created playlist '%s' with %d tracks!\n", runSaveAs, len(tracks))
+		}
+	}
+
+	applyPlaybackFlags(ctx, svc, tracks, runQueueTracks, runPlayTracks)
+
+	return nil
+}
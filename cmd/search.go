@@ -9,8 +9,11 @@ import (
 	"strings"
 
 	"github.com/lorrehuggan/moodify/internal/ai"
-	"github.com/lorrehuggan/moodify/internal/auth"
+	"github.com/lorrehuggan/moodify/internal/arc"
+	"github.com/lorrehuggan/moodify/internal/history"
+	"github.com/lorrehuggan/moodify/internal/sequence"
 	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
 	"github.com/spf13/cobra"
 	"github.com/zmb3/spotify/v2"
 )
@@ -19,11 +22,16 @@ var limit int
 var market string
 var saveToPlaylist string
 var makePublic bool
-var verbose bool
+var queueResults bool
+var playResults bool
+var searchSequence string
+var searchArc string
+var searchInteractive bool
+var searchPreset string
 
 func init() {
-	searchCmd := &cobra.Command{
-		Use:   "search <free text query>",
+	searchCmd := withScopes(&cobra.Command{
+		Use:   "search <free text query> | --preset <name>",
 		Short: "Search Spotify using natural language",
 		Long: `Search Spotify using natural language descriptions of mood, genre, and era.
 
@@ -38,16 +46,34 @@ Examples:
   moodify search sad 90s alternative rock
   moodify search aggressive metal for gym
   moodify search nostalgic dreamy shoegaze  # AI mode understands this better
+  moodify search late night jazz --play     # Start playing the results right away
+  moodify search dreamy synthwave -i        # Browse, pick and save results interactively
+  moodify search deep house --sequence harmonic --save "Mix"  # DJ-style ordering
+  moodify search indie folk --duration 35m  # Fill 35 minutes as closely as possible
+  moodify search house --arc warmup-peak-cooldown --duration 45m  # Shape the energy
+  moodify search --preset focus             # Use a saved preset instead of a vibe
 
 Use --verbose to see which parsing mode is active and view parsed attributes.`,
-		Args: cobra.MinimumNArgs(1),
+		Args: vibeOrPresetArgs(&searchPreset),
 		RunE: runSearch,
-	}
+	}, discoveryScopes...)
 	searchCmd.Flags().IntVarP(&limit, "limit", "n", 15, "Number of tracks to return (1-100)")
 	searchCmd.Flags().StringVar(&market, "market", "US", "ISO market code (e.g., US, GB)")
 	searchCmd.Flags().StringVar(&saveToPlaylist, "save", "", "Save results to a new playlist with this name")
 	searchCmd.Flags().BoolVar(&makePublic, "public", false, "Make the saved playlist public (default: private)")
-	searchCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed processing information including AI parsing details")
+	searchCmd.Flags().BoolVar(&queueResults, "queue", false, "Add the results to your playback queue")
+	searchCmd.Flags().BoolVar(&playResults, "play", false, "Start playing the results immediately")
+	searchCmd.Flags().StringVar(&searchSequence, "sequence", "", "Reorder the results for smooth transitions (harmonic)")
+	searchCmd.Flags().StringVar(&searchArc, "arc", "", "Shape the playlist's energy, e.g. warmup-peak-cooldown or workout (needs --duration)")
+	searchCmd.Flags().BoolVarP(&searchInteractive, "interactive", "i", false, "Browse the results interactively to preview, play, queue and save them")
+	searchCmd.Flags().StringVar(&searchPreset, "preset", "", "Use a saved preset's filters and seeds instead of a query (see 'moodify preset list')")
+	addDurationFlags(searchCmd)
+	searchCmd.MarkFlagsMutuallyExclusive("queue", "play")
+	searchCmd.MarkFlagsMutuallyExclusive("arc", "sequence")
+	searchCmd.MarkFlagsMutuallyExclusive("interactive", "save")
+	searchCmd.MarkFlagsMutuallyExclusive("interactive", "queue")
+	searchCmd.MarkFlagsMutuallyExclusive("interactive", "play")
+	addOutputFlag(searchCmd)
 	rootCmd.AddCommand(searchCmd)
 }
 
@@ -55,183 +81,193 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	query := strings.Join(args, " ")
 	ctx := context.Background()
 
-	// 1) Check if user is authenticated
-	if !auth.QuickCheck() {
-		fmt.Println("🔐 Authentication required!")
-		fmt.Println("Run this command to get started: moodify login")
-		fmt.Println()
-		return fmt.Errorf("not authenticated - run 'moodify login' first")
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+	if jsonOutput() && (saveToPlaylist != "" || queueResults || playResults || searchInteractive) {
+		return fmt.Errorf("--output json can't be combined with --save, --queue, --play or --interactive")
+	}
+	if searchSequence != "" && searchSequence != sequenceHarmonic {
+		return fmt.Errorf("invalid --sequence %q (supported: %s)", searchSequence, sequenceHarmonic)
+	}
+	if err := validateDurationFlags(); err != nil {
+		return err
+	}
+	if searchArc != "" {
+		if _, err := arc.Parse(searchArc); err != nil {
+			return err
+		}
+		if targetDuration <= 0 {
+			return fmt.Errorf("--arc needs a target --duration, e.g. --duration 45m")
+		}
 	}
 
-	// 2) Get authenticated Spotify client
-	config := &auth.Config{
-		ClientID:    auth.GetClientIDFromEnv(),
-		RedirectURI: "http://127.0.0.1:8808/callback",
-		Port:        "8808",
-		Scopes: []string{
-			"user-top-read",
-			"playlist-modify-private",
-			"user-read-private",
-		},
+	client := spotifyClient(cmd)
+	if searchInteractive {
+		// Adding to existing playlists needs to look them up
+		if err := requireScopes(playlistModifyScopes...); err != nil {
+			return err
+		}
 	}
 
-	client, err := auth.GetAuthenticatedClient(ctx, config)
-	if err != nil {
-		fmt.Println("❌ Token expired or invalid. Please re-authenticate:")
-		fmt.Println("   moodify login")
-		return fmt.Errorf("authentication failed: %w", err)
+	// 1) Parse natural language → filters, or take them from a preset
+	if searchPreset != "" {
+		p, err := moodify.LoadPreset(searchPreset)
+		if err != nil {
+			return err
+		}
+		parsed := moodify.ParsePreset(p)
+		if !jsonOutput() {
+			fmt.Printf("🎚️  Using preset '%s': %s\n", p.Name, describePreset(p))
+			if verbose {
+				fmt.Printf("   %s\n", strings.Join(filterSummary(parsed.Filters), " · "))
+			}
+		}
+		return searchWithFilters(ctx, client, parsed, moodify.PresetSeeds(p), 0)
 	}
 
-	// 3) Parse natural language → filters
 	if verbose {
 		fmt.Printf("🎯 Analyzing query: %q\n", query)
 	}
 
 	// Check if OpenAI is available and notify user
 	openaiEnabled := os.Getenv("OPENAI_API_KEY") != ""
-	if openaiEnabled {
+	// (skipped for JSON output, which keeps stdout for the JSON document)
+	if openaiEnabled && !jsonOutput() {
 		fmt.Println("🤖 Using AI-powered query parsing (OpenAI GPT-4o-mini)")
 		if verbose {
 			fmt.Println("   This provides enhanced understanding of mood, genre, and musical attributes")
 		}
-	} else {
+	} else if !jsonOutput() {
 		fmt.Println("📝 Using basic keyword parsing")
 		if verbose {
 			fmt.Println("   For smarter results, set OPENAI_API_KEY environment variable")
 		}
 	}
 
-	filters, err := ai.ParseQuery(ctx, query)
-	if err != nil {
-		if openaiEnabled {
+	parsed := moodify.Parse(ctx, query)
+	if err := parsed.AIError; err != nil {
+		if openaiEnabled && !jsonOutput() {
 			fmt.Printf("⚠️  AI parsing failed, falling back to basic parsing\n")
 			if verbose {
 				fmt.Printf("   Error: %v\n", err)
 			}
 		}
 		log.Printf("AI parse failed, falling back to simple parser: %v", err)
-		filters = ai.SimpleParse(query)
 	}
-
-	if verbose {
-		fmt.Printf("🎼 Parsed filters:\n")
-		if len(filters.Genres) > 0 {
-			fmt.Printf("   Genres: %v\n", filters.Genres)
-		}
-		if filters.MinEnergy > 0 || filters.MaxEnergy < 1.0 {
-			fmt.Printf("   Energy: %.2f - %.2f\n", filters.MinEnergy, filters.MaxEnergy)
-		}
-		if filters.MinValence > 0 || filters.MaxValence < 1.0 {
-			fmt.Printf("   Mood (valence): %.2f - %.2f\n", filters.MinValence, filters.MaxValence)
-		}
-		if filters.MinDanceability > 0 || filters.MaxDanceability < 1.0 {
-			fmt.Printf("   Danceability: %.2f - %.2f\n", filters.MinDanceability, filters.MaxDanceability)
-		}
-		if filters.YearStart > 0 || filters.YearEnd > 0 {
-			fmt.Printf("   Year range: %d - %d\n", filters.YearStart, filters.YearEnd)
-		}
+	if verbose && !jsonOutput() {
+		printParsedFilters(parsed.Filters)
 		fmt.Println()
 	}
 
-	// 3) Build recommendation seeds + tuneable attributes
-	seeds := spotify.Seeds{
-		// Smart defaults: prefer up to 5 total across artists/genres/tracks
-		Genres: filters.Genres, // []string
-	}
-
-	// Validate and clean up genres - remove any that might be invalid
-	validGenres := validateGenres(seeds.Genres)
-	seeds.Genres = validGenres
-
-	// If no valid genres from parsing, seed by user's top artists as a nice fallback:
-	if len(seeds.Genres) == 0 {
-		top, err := client.CurrentUsersTopArtists(ctx, spotify.Limit(3))
-		if err == nil && len(top.Artists) > 0 {
-			for i, a := range top.Artists {
-				if i >= 2 { // Limit to 2 artist seeds to leave room for genres if needed
-					break
-				}
-				seeds.Artists = append(seeds.Artists, a.ID)
-			}
-		}
-	}
-
-	// Ensure we have at least one seed - use popular genres as last resort
-	totalSeeds := len(seeds.Genres) + len(seeds.Artists) + len(seeds.Tracks)
-	if totalSeeds == 0 {
-		seeds.Genres = []string{"pop"}
-	}
+	return searchWithFilters(ctx, client, parsed, nil, 0)
+}
 
-	// Ensure we don't exceed Spotify's limit of 5 seeds total
-	if totalSeeds > 5 {
-		if len(seeds.Genres) > 3 {
-			seeds.Genres = seeds.Genres[:3]
-		}
-		if len(seeds.Artists) > 2 {
-			seeds.Artists = seeds.Artists[:2]
-		}
-	}
+// searchWithFilters finds, shows and records tracks for a parsed query, using
+// seeds when given instead of building them. replayOf is the recorded search
+// being re-run, if any.
+func searchWithFilters(ctx context.Context, client *spotify.Client, parsed *moodify.ParseResult, seeds *spotify.Seeds, replayOf int) error {
+	query, filters := parsed.Query, parsed.Filters
 
 	// Year/era constraint via seed query trick:
 	// Spotify recs don't accept year directly; we'll post-filter if provided.
-	hasYearFilter := filters.YearStart > 0 || filters.YearEnd > 0
-
-	// 4) Try recommendations API first, fall back to search if it fails
-	recs, err := spotifyx.GetRecommendationsWithFilters(ctx, client, seeds,
-		filters.MinDanceability, filters.MaxDanceability,
-		filters.MinEnergy, filters.MaxEnergy,
-		filters.MinValence, filters.MaxValence,
-		filters.MinTempo, filters.MaxTempo,
-		filters.MinPopularity, filters.MaxPopularity,
-		limit, market)
-
+	svc := newService(client)
 	var tracks []spotify.SimpleTrack
+	var arcPlaylist *arcResult
 
-	if err != nil {
-		// Fallback to search-based approach
-		searchResults, searchErr := searchBasedFallback(ctx, client, query, filters, limit)
-		if searchErr != nil {
-			return fmt.Errorf("music discovery failed - please try a different search or try again later")
+	if searchArc != "" {
+		// 2) Fetch candidates per arc segment and assemble them to the target duration
+		if seeds == nil {
+			built := svc.Seeds(ctx, filters)
+			seeds = &built
+		}
+		var err error
+		arcPlaylist, err = buildArcPlaylist(ctx, svc, query, filters, *seeds, searchArc, targetDuration)
+		if err != nil {
+			return err
 		}
-		tracks = searchResults
+		tracks = arcPlaylist.Tracks
 	} else {
-		tracks = recs.Tracks
+		// 2) Try recommendations API first, fall back to search if it fails, and
+		// pick tracks to fill the target length if one was given
+		result, err := svc.Search(ctx, query, moodify.SearchOptions{
+			Limit:   limit,
+			Filters: &filters,
+			Seeds:   seeds,
+			Fit:     durationOptions(),
+		})
+		if err != nil {
+			return err
+		}
+		tracks, seeds = result.Items, &result.Seeds
+	}
+
+	// 3) Print results
+	if len(tracks) == 0 && !jsonOutput() {
+		fmt.Println("No tracks matched your vibe. Try loosening the query.")
+		return nil
 	}
 
-	// Optional: post-filter by release year if user mentioned an era (if not already done in fallback)
-	if hasYearFilter {
-		filtered := make([]spotify.SimpleTrack, 0, len(tracks))
-		for _, t := range tracks {
-			yr := spotifyx.ParseYear(t.Album.ReleaseDate)
-			if (filters.YearStart == 0 || yr >= filters.YearStart) &&
-				(filters.YearEnd == 0 || yr <= filters.YearEnd) {
-				filtered = append(filtered, t)
+	// Optionally reorder the results so they mix into each other
+	var sequenced *sequence.Result
+	if searchSequence == sequenceHarmonic {
+		ordered, result, err := sequenceTracks(ctx, client, tracks, sequence.Options{})
+		if err != nil {
+			if !jsonOutput() {
+				fmt.Printf("⚠️  Couldn't sequence the results: %v\n", err)
 			}
+		} else {
+			tracks, sequenced = ordered, result
 		}
-		tracks = filtered
 	}
 
-	// 5) Print results
-	if len(tracks) == 0 {
-		fmt.Println("No tracks matched your vibe. Try loosening the query.")
-		return nil
+	recorded := recordSearch(&history.Search{
+		Command:  history.CommandSearch,
+		Query:    query,
+		Parser:   parsed.Parser,
+		Filters:  &filters,
+		Seeds:    seedsRecord(*seeds),
+		Limit:    limit,
+		Arc:      searchArc,
+		Sequence: searchSequence,
+		ReplayOf: replayOf,
+	}, tracks)
+
+	if jsonOutput() {
+		return printJSON(moodify.NewSearchResult(query, filters, tracks))
+	}
+
+	if searchInteractive {
+		if browsed, err := tryBrowseTracks(ctx, client, fmt.Sprintf("Results for: %q", query), tracks); browsed {
+			return err
+		}
 	}
 
 	fmt.Printf("\n🎧 Results for: %q  (%d tracks)\n\n", query, len(tracks))
-	for i, t := range tracks {
-		artist := "Unknown"
-		if len(t.Artists) > 0 {
-			artist = t.Artists[0].Name
+	printSearchTracks(tracks)
+
+	if arcPlaylist != nil {
+		fmt.Println()
+		printArcSummary(arcPlaylist)
+	} else if targetDuration > 0 {
+		fmt.Println()
+		printRuntime(tracks)
+	}
+
+	if sequenced != nil {
+		fmt.Println()
+		if verbose {
+			printTransitionReport(sequenced)
+		} else {
+			fmt.Printf("🎛️  Sequenced for harmonic mixing (transition cost %.2f → %.2f, --verbose for details)\n",
+				sequenced.OriginalCost, sequenced.TotalCost)
 		}
-		year := spotifyx.ParseYear(t.Album.ReleaseDate)
-		fmt.Printf("%2d. %s — %s  (%d)\n    %s\n",
-			i+1, t.Name, artist, year, t.ExternalURLs["spotify"])
 	}
 
 	// Save to playlist if requested
 	if saveToPlaylist != "" {
 		fmt.Printf("\n💾 Saving to playlist: %s\n", saveToPlaylist)
-		if err := createPlaylistFromTracks(ctx, client, tracks, saveToPlaylist, makePublic); err != nil {
+		if _, err := svc.SavePlaylist(ctx, saveToPlaylist, tracks, moodify.SavePlaylistOptions{Public: makePublic}); err != nil {
 			fmt.Printf("❌ Failed to create playlist: %v\n", err)
 		} else {
 			visibility := "private"
@@ -242,161 +278,48 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Queue or play results if requested
+	applyPlaybackFlags(ctx, svc, tracks, queueResults, playResults)
+
+	printRecordedSearch(recorded)
 	return nil
 }
 
-// validateGenres filters out potentially invalid genre names
-func validateGenres(genres []string) []string {
-	// Known good Spotify recommendation genres (a subset of commonly used ones)
-	validGenres := map[string]bool{
-		"acoustic": true, "afrobeat": true, "alt-rock": true, "alternative": true,
-		"ambient": true, "blues": true, "bossanova": true, "brazil": true,
-		"breakbeat": true, "british": true, "chill": true, "classical": true,
-		"club": true, "country": true, "dance": true, "dancehall": true,
-		"deep-house": true, "disco": true, "drum-and-bass": true, "dub": true,
-		"dubstep": true, "edm": true, "electronic": true, "folk": true,
-		"funk": true, "garage": true, "gospel": true, "groove": true,
-		"hip-hop": true, "house": true, "indie": true, "indie-pop": true,
-		"jazz": true, "latin": true, "metal": true, "pop": true,
-		"punk": true, "r-n-b": true, "reggae": true, "rock": true,
-		"soul": true, "techno": true, "trance": true, "world-music": true,
-	}
-
-	var result []string
-	for _, genre := range genres {
-		if validGenres[strings.ToLower(genre)] {
-			result = append(result, strings.ToLower(genre))
+// printSearchTracks lists tracks the way search results are shown
+func printSearchTracks(tracks []spotify.SimpleTrack) {
+	for i, t := range tracks {
+		artist := "Unknown"
+		if len(t.Artists) > 0 {
+			artist = t.Artists[0].Name
 		}
+		year := spotifyx.ParseYear(t.Album.ReleaseDate)
+		fmt.Printf("%2d. %s — %s  (%d)\n    %s\n",
+			i+1, t.Name, artist, year, t.ExternalURLs["spotify"])
 	}
-
-	return result
 }
 
-// createPlaylistFromTracks creates a new Spotify playlist with the given tracks
-func createPlaylistFromTracks(ctx context.Context, client *spotify.Client, tracks []spotify.SimpleTrack, name string, public bool) error {
-	// Get current user
-	user, err := client.CurrentUser(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get current user: %w", err)
+// printParsedFilters lists the attributes a set of filters constrains
+func printParsedFilters(filters ai.Filters) {
+	fmt.Printf("🎼 Parsed filters:\n")
+	if len(filters.Genres) > 0 {
+		fmt.Printf("   Genres: %v\n", filters.Genres)
 	}
-
-	// Create playlist description
-	description := fmt.Sprintf("Generated by Moodify - %d tracks discovered through natural language search", len(tracks))
-
-	// Create playlist
-	playlist, err := client.CreatePlaylistForUser(ctx, user.ID, name, description, public, false)
-	if err != nil {
-		return fmt.Errorf("failed to create playlist: %w", err)
+	if len(filters.ExcludeGenres) > 0 {
+		fmt.Printf("   Excluded: %v\n", filters.ExcludeGenres)
 	}
-
-	// Convert SimpleTrack to track IDs for playlist addition
-	var trackIDs []spotify.ID
-	for _, track := range tracks {
-		// Extract ID from URI (format: spotify:track:ID)
-		uriParts := strings.Split(string(track.URI), ":")
-		if len(uriParts) >= 3 {
-			trackIDs = append(trackIDs, spotify.ID(uriParts[2]))
-		}
+	if filters.MinEnergy > 0 || filters.MaxEnergy < 1.0 {
+		fmt.Printf("   Energy: %.2f - %.2f\n", filters.MinEnergy, filters.MaxEnergy)
 	}
-
-	if len(trackIDs) == 0 {
-		return fmt.Errorf("no valid track IDs found")
+	if filters.MinValence > 0 || filters.MaxValence < 1.0 {
+		fmt.Printf("   Mood (valence): %.2f - %.2f\n", filters.MinValence, filters.MaxValence)
 	}
-
-	// Add tracks to playlist (Spotify API limits to 100 tracks per request)
-	const batchSize = 100
-	for i := 0; i < len(trackIDs); i += batchSize {
-		end := i + batchSize
-		if end > len(trackIDs) {
-			end = len(trackIDs)
-		}
-
-		batch := trackIDs[i:end]
-		_, err = client.AddTracksToPlaylist(ctx, playlist.ID, batch...)
-		if err != nil {
-			return fmt.Errorf("failed to add tracks to playlist (batch %d-%d): %w", i+1, end, err)
-		}
-	}
-
-	return nil
-}
-
-// searchBasedFallback implements music discovery using Spotify's search API when recommendations fail
-func searchBasedFallback(ctx context.Context, client *spotify.Client, originalQuery string, filters ai.Filters, limit int) ([]spotify.SimpleTrack, error) {
-	// Build search query based on parsed filters
-	searchQuery := buildSearchQuery(originalQuery, filters)
-
-	// Search for tracks
-	results, err := client.Search(ctx, searchQuery, spotify.SearchTypeTrack, spotify.Limit(limit*2)) // Get more results to filter
-	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
-
-	if len(results.Tracks.Tracks) == 0 {
-		return nil, fmt.Errorf("no tracks found")
-	}
-
-	// Convert FullTrack to SimpleTrack and apply filters
-	var tracks []spotify.SimpleTrack
-	for _, fullTrack := range results.Tracks.Tracks {
-		// Convert to SimpleTrack
-		simpleTrack := spotify.SimpleTrack{
-			Artists: make([]spotify.SimpleArtist, len(fullTrack.Artists)),
-			Album: spotify.SimpleAlbum{
-				Name:        fullTrack.Album.Name,
-				ReleaseDate: fullTrack.Album.ReleaseDate,
-				ID:          fullTrack.Album.ID,
-			},
-			ExternalURLs: fullTrack.ExternalURLs,
-			ID:           fullTrack.ID,
-			Name:         fullTrack.Name,
-			URI:          fullTrack.URI,
-		}
-
-		// Convert artists
-		for i, artist := range fullTrack.Artists {
-			simpleTrack.Artists[i] = spotify.SimpleArtist{
-				ID:   artist.ID,
-				Name: artist.Name,
-			}
-		}
-
-		// Apply year filter if specified
-		if filters.YearStart > 0 || filters.YearEnd > 0 {
-			year := spotifyx.ParseYear(simpleTrack.Album.ReleaseDate)
-			if (filters.YearStart > 0 && year < filters.YearStart) ||
-				(filters.YearEnd > 0 && year > filters.YearEnd) {
-				continue // Skip tracks outside year range
-			}
-		}
-
-		tracks = append(tracks, simpleTrack)
-		if len(tracks) >= limit {
-			break // We have enough tracks
-		}
+	if filters.MinDanceability > 0 || filters.MaxDanceability < 1.0 {
+		fmt.Printf("   Danceability: %.2f - %.2f\n", filters.MinDanceability, filters.MaxDanceability)
 	}
-
-	return tracks, nil
-}
-
-// buildSearchQuery creates a search string from the original query and parsed filters
-func buildSearchQuery(originalQuery string, filters ai.Filters) string {
-	query := originalQuery
-
-	// Add genre information to search if available
-	if len(filters.Genres) > 0 {
-		// Add the first genre to the search query
-		query += " genre:" + filters.Genres[0]
+	if filters.MinTempo > 0 || filters.MaxTempo > 0 {
+		fmt.Printf("   Tempo: %.0f - %.0f BPM\n", filters.MinTempo, filters.MaxTempo)
 	}
-
-	// Add year range if specified
-	if filters.YearStart > 0 && filters.YearEnd > 0 {
-		query += fmt.Sprintf(" year:%d-%d", filters.YearStart, filters.YearEnd)
-	} else if filters.YearStart > 0 {
-		query += fmt.Sprintf(" year:%d-2024", filters.YearStart)
-	} else if filters.YearEnd > 0 {
-		query += fmt.Sprintf(" year:1950-%d", filters.YearEnd)
+	if filters.YearStart > 0 || filters.YearEnd > 0 {
+		fmt.Printf("   Year range: %d - %d\n", filters.YearStart, filters.YearEnd)
 	}
-
-	return query
 }
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/arc"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	// arcCandidatesPerSegment is how many recommendations are fetched per arc segment
+	arcCandidatesPerSegment = 50
+	// arcEnergyRange is how far a segment's candidates may stray from its target energy
+	arcEnergyRange = 0.15
+	// defaultArcTempo is the base tempo when the query doesn't imply one
+	defaultArcTempo = 120
+)
+
+// arcResult is an assembled arc playlist with what's needed to chart it
+type arcResult struct {
+	Plan     *arc.Plan
+	Tracks   []spotify.SimpleTrack
+	Energies []float64 // achieved energy per track, -1 when unknown
+}
+
+// buildArcPlaylist fetches candidates for every segment of the arc, with
+// segment-specific energy and tempo targets, and assembles them into a
+// playlist matching the plan's duration
+func buildArcPlaylist(ctx context.Context, svc *moodify.Service, query string, filters ai.Filters, seeds spotify.Seeds, shape string, duration time.Duration) (*arcResult, error) {
+	baseTempo := float64(defaultArcTempo)
+	if filters.MinTempo > 0 && filters.MaxTempo > 0 {
+		baseTempo = (filters.MinTempo + filters.MaxTempo) / 2
+	}
+
+	plan, err := arc.NewPlan(shape, duration, baseTempo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gather candidates per distinct segment, remembering which energy each
+	// was recommended for in case audio features aren't available
+	var pool []spotify.SimpleTrack
+	targetEnergy := map[spotify.ID]float64{}
+	fetched := map[string]bool{}
+	for _, segment := range plan.Segments {
+		if fetched[segment.Name] {
+			continue
+		}
+		fetched[segment.Name] = true
+
+		recs, err := spotifyx.GetRecommendationsForTarget(ctx, svc.Client(), seeds,
+			segment.Energy, arcEnergyRange, segment.Tempo,
+			filters.MinPopularity, arcCandidatesPerSegment, svc.Market())
+		if err != nil {
+			if verbose {
+				fmt.Printf("⚠️  Recommendations for the %s segment failed: %v\n", segment.Name, err)
+			}
+			continue
+		}
+		for _, t := range recs.Tracks {
+			if _, seen := targetEnergy[t.ID]; !seen {
+				targetEnergy[t.ID] = segment.Energy
+				pool = append(pool, t)
+			}
+		}
+	}
+
+	// Without recommendations, fall back to a plain search and rely on audio features
+	if len(pool) == 0 {
+		tracks, err := svc.SearchTracks(ctx, query, filters, arcCandidatesPerSegment)
+		if err != nil {
+			return nil, fmt.Errorf("no recommendations for any segment, and the search fallback failed: %w", err)
+		}
+		pool = tracks
+	}
+
+	pool = moodify.FilterByYear(pool, filters)
+
+	ids := make([]spotify.ID, 0, len(pool))
+	for _, t := range pool {
+		ids = append(ids, t.ID)
+	}
+	features, err := spotifyx.GetAudioFeaturesBatch(ctx, svc.Client(), ids)
+	if err != nil && verbose {
+		fmt.Printf("⚠️  Audio features unavailable, using recommendation targets: %v\n", err)
+	}
+
+	candidates := make([]arc.Candidate, 0, len(pool))
+	for i, t := range pool {
+		c := arc.Candidate{Index: i, Duration: t.TimeDuration(), Energy: -1}
+		if f := features[t.ID]; f != nil {
+			c.Energy, c.Tempo = float64(f.Energy), float64(f.Tempo)
+		} else if energy, ok := targetEnergy[t.ID]; ok {
+			c.Energy = energy
+		} else {
+			continue // nothing to place it by
+		}
+		candidates = append(candidates, c)
+	}
+
+	result := &arcResult{Plan: plan}
+	for _, p := range plan.Assemble(candidates) {
+		result.Tracks = append(result.Tracks, pool[p.Index])
+		energy := -1.0
+		if f := features[pool[p.Index].ID]; f != nil {
+			energy = float64(f.Energy)
+		}
+		result.Energies = append(result.Energies, energy)
+	}
+	return result, nil
+}
+
+// printArcSummary charts the planned and achieved energy curves and the runtime
+func printArcSummary(result *arcResult) {
+	const width = 48
+
+	var total time.Duration
+	for _, t := range result.Tracks {
+		total += t.TimeDuration()
+	}
+	span := result.Plan.Duration
+	if total > span {
+		span = total
+	}
+
+	planned := make([]float64, width)
+	achieved := make([]float64, width)
+	for col := 0; col < width; col++ {
+		at := time.Duration(float64(span) * (float64(col) + 0.5) / width)
+		planned[col] = -1
+		if at < result.Plan.Duration {
+			planned[col] = result.Plan.EnergyAt(at)
+		}
+
+		achieved[col] = -1
+		var elapsed time.Duration
+		for i, t := range result.Tracks {
+			elapsed += t.TimeDuration()
+			if at < elapsed {
+				achieved[col] = result.Energies[i]
+				break
+			}
+		}
+	}
+
+	fmt.Printf("📈 Energy arc: %s\n", result.Plan.Shape)
+	printEnergyCurve(planned, achieved, 8)
+	fmt.Println()
+
+	for _, segment := range result.Plan.Segments {
+		fmt.Printf("   %-9s %7s  energy %.2f • ~%.0f BPM\n",
+			segment.Name, formatRuntime(segment.Duration), segment.Energy, segment.Tempo)
+	}
+	fmt.Printf("⏱️  Total runtime: %s (target %s)\n", formatRuntime(total), formatRuntime(result.Plan.Duration))
+}
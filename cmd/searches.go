@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/history"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+var (
+	searchesLimit int
+	replayFresh   bool
+	saveName      string
+	savePublic    bool
+)
+
+func init() {
+	replayCmd := withScopes(&cobra.Command{
+		Use:   "replay [id]",
+		Short: "Show a past search or discovery again",
+		Long: `Show the tracks a past search or discovery found, without searching again.
+The ID comes from 'moodify history searches'; it defaults to the last one.
+
+Use --fresh to run it again with the same filters and seeds for new results.
+AI parsing isn't repeated, so the vibe is read exactly as before.
+
+Examples:
+  moodify replay
+  moodify replay 12
+  moodify replay 12 --fresh`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runReplay,
+	}, discoveryScopes...)
+	replayCmd.Flags().BoolVar(&replayFresh, "fresh", false, "Search again with the same filters instead of showing the recorded tracks")
+	addOutputFlag(replayCmd)
+
+	saveCmd := withScopes(&cobra.Command{
+		Use:   "save <id> --name <playlist>",
+		Short: "Save a past search or discovery as a playlist",
+		Long: `Create a playlist from the tracks a past search or discovery found, exactly
+as they were shown. The ID comes from 'moodify history searches', or use last.
+
+Examples:
+  moodify save 12 --name "Rainy Day Indie"
+  moodify save last --name "Gym" --public`,
+		Args: cobra.ExactArgs(1),
+		RunE: runSaveSearch,
+	}, playlistCreateScopes...)
+	saveCmd.Flags().StringVar(&saveName, "name", "", "Name of the new playlist")
+	saveCmd.Flags().BoolVar(&savePublic, "public", false, "Make the playlist public (default: private)")
+	_ = saveCmd.MarkFlagRequired("name")
+
+	rootCmd.AddCommand(replayCmd, saveCmd)
+}
+
+// newSearchesCmd returns `moodify history searches`
+func newSearchesCmd() *cobra.Command {
+	searchesCmd := &cobra.Command{
+		Use:   "searches",
+		Short: "List past searches and discoveries",
+		Long: `List the searches and discoveries moodify has recorded, with their IDs for
+'moodify replay' and 'moodify save'.
+
+Every search and discover run is recorded with its query, parsed filters,
+recommendation seeds and the tracks it found.`,
+		Args: cobra.NoArgs,
+		RunE: runHistorySearches,
+	}
+	searchesCmd.Flags().IntVarP(&searchesLimit, "limit", "n", 20, "Number of most recent searches to show (0 for all)")
+	addOutputFlag(searchesCmd)
+	return searchesCmd
+}
+
+func runHistorySearches(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	store, err := history.OpenSearches()
+	if err != nil {
+		return err
+	}
+	searches, err := store.List(searchesLimit)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if searches == nil {
+			searches = []history.Search{}
+		}
+		return printJSON(searches)
+	}
+
+	fmt.Println("🔎 Search History")
+	fmt.Println("═════════════════")
+	fmt.Println()
+
+	if len(searches) == 0 {
+		fmt.Println("📭 No searches recorded yet")
+		fmt.Println()
+		fmt.Println("💡 Run 'moodify search <vibe>' or 'moodify discover' and it will show up here")
+		return nil
+	}
+
+	for _, search := range searches {
+		fmt.Printf("   #%-4d %s  %-8s  %s  (%d tracks)\n",
+			search.ID, search.At.Local().Format("Jan 02 15:04"), search.Command,
+			describeSearch(&search), len(search.TrackIDs))
+	}
+
+	fmt.Println()
+	fmt.Println("💡 See one again: moodify replay <id>   New results: moodify replay <id> --fresh")
+	fmt.Println("   Keep one:       moodify save <id> --name \"Playlist name\"")
+	return nil
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	ref := "last"
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	search, err := loadSearch(ref)
+	if err != nil {
+		return err
+	}
+	client := spotifyClient(cmd)
+
+	if replayFresh {
+		restoreSearchOptions(search)
+		if !jsonOutput() {
+			fmt.Printf("🔁 Running %s #%d again: %s\n", search.Command, search.ID, describeSearch(search))
+		}
+
+		if search.Command == history.CommandDiscover {
+			return discoverWithCriteria(ctx, client, criteriaFromRecord(search.Criteria), search.ID)
+		}
+		parsed := &moodify.ParseResult{Query: search.Query, Parser: search.Parser}
+		if search.Filters != nil {
+			parsed.Filters = *search.Filters
+		}
+		return searchWithFilters(ctx, client, parsed, seedsFromRecord(search.Seeds), search.ID)
+	}
+
+	tracks, err := spotifyx.GetTracksBatch(ctx, client, recordedTrackIDs(search))
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(struct {
+			Search    *history.Search `json:"search"`
+			Tracks    []moodify.Track `json:"tracks"`
+			RuntimeMs int64           `json:"runtime_ms"`
+		}{search, moodify.NewTracks(tracks), moodify.Runtime(tracks).Milliseconds()})
+	}
+
+	fmt.Printf("🔁 %s #%d from %s: %s\n\n", strings.ToUpper(search.Command[:1])+search.Command[1:],
+		search.ID, search.At.Local().Format("Mon Jan 2 15:04"), describeSearch(search))
+	printSearchTracks(tracks)
+	if missing := len(search.TrackIDs) - len(tracks); missing > 0 {
+		fmt.Printf("\n⚠️  %d tracks are no longer available on Spotify\n", missing)
+	}
+
+	fmt.Println()
+	fmt.Printf("⏱️  Total runtime: %s\n", formatRuntime(moodify.Runtime(tracks)))
+	fmt.Printf("💡 New results with the same filters: moodify replay %d --fresh\n", search.ID)
+	return nil
+}
+
+func runSaveSearch(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	search, err := loadSearch(args[0])
+	if err != nil {
+		return err
+	}
+	if len(search.TrackIDs) == 0 {
+		return fmt.Errorf("%s #%d found no tracks to save", search.Command, search.ID)
+	}
+
+	// The recorded IDs are all a playlist needs, so nothing is searched again
+	tracks := make([]spotify.SimpleTrack, 0, len(search.TrackIDs))
+	for _, id := range recordedTrackIDs(search) {
+		tracks = append(tracks, spotify.SimpleTrack{ID: id, URI: spotify.URI("spotify:track:" + id)})
+	}
+
+	fmt.Printf("💾 Saving %s #%d to playlist: %s\n", search.Command, search.ID, saveName)
+	playlist, err := newService(spotifyClient(cmd)).SavePlaylist(ctx, saveName, tracks, moodify.SavePlaylistOptions{
+		Public:      savePublic,
+		Description: fmt.Sprintf("Generated by Moodify from %s", describeSearch(search)),
+	})
+	if err != nil {
+		return err
+	}
+
+	visibility := "private"
+	if savePublic {
+		visibility = "public"
+	}
+	fmt.Printf("✅ Created %s playlist '%s' with %d tracks!\n", visibility, playlist.Name, playlist.Tracks)
+	if playlist.URL != "" {
+		fmt.Printf("   🔗 %s\n", playlist.URL)
+	}
+	return nil
+}
+
+// loadSearch finds a recorded search by ID, or the last one
+func loadSearch(ref string) (*history.Search, error) {
+	store, err := history.OpenSearches()
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(ref)
+}
+
+// recordSearch adds a search and the tracks it found to the search history,
+// along with the market and --duration flags. Failing to record doesn't fail
+// the search, so it returns nil then.
+func recordSearch(search *history.Search, tracks []spotify.SimpleTrack) *history.Search {
+	search.Market = market
+	if targetDuration > 0 {
+		search.TargetMs = targetDuration.Milliseconds()
+		search.ToleranceMs = durationTolerance.Milliseconds()
+		search.FitMethod = durationFit
+	}
+	search.TrackIDs = make([]string, 0, len(tracks))
+	for _, t := range tracks {
+		search.TrackIDs = append(search.TrackIDs, string(t.ID))
+	}
+
+	store, err := history.OpenSearches()
+	if err == nil {
+		err = store.Add(search)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Couldn't record this search: %v\n", err)
+		return nil
+	}
+	return search
+}
+
+// printRecordedSearch tells the user how to come back to a recorded search
+func printRecordedSearch(search *history.Search) {
+	if search == nil {
+		return
+	}
+	fmt.Printf("\n🔖 Recorded as #%d: see it again with 'moodify replay %d', or keep it with 'moodify save %d --name <playlist>'\n",
+		search.ID, search.ID, search.ID)
+}
+
+// restoreSearchOptions sets the flags a recorded search ran with, for running it again
+func restoreSearchOptions(search *history.Search) {
+	if search.Market != "" {
+		market = search.Market
+	}
+	if search.Limit > 0 {
+		limit, discoverLimit = search.Limit, search.Limit
+	}
+	targetDuration = time.Duration(search.TargetMs) * time.Millisecond
+	if search.ToleranceMs > 0 {
+		durationTolerance = time.Duration(search.ToleranceMs) * time.Millisecond
+	}
+	if search.FitMethod != "" {
+		durationFit = search.FitMethod
+	}
+	searchArc, searchSequence = search.Arc, search.Sequence
+}
+
+// describeSearch summarises what a search looked for
+func describeSearch(search *history.Search) string {
+	if search.Command == history.CommandSearch {
+		return fmt.Sprintf("%q", search.Query)
+	}
+
+	var parts []string
+	for _, key := range criteriaKeys {
+		if value := search.Criteria[key]; value != "" {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	if len(parts) > 0 {
+		return strings.Join(parts, " ")
+	}
+	if len(search.Seeds.Genres) > 0 {
+		return "random genres: " + strings.Join(search.Seeds.Genres, ", ")
+	}
+	return "based on your taste"
+}
+
+// criteriaKeys are the discover flags recorded as criteria, in display order
+var criteriaKeys = []string{"preset", "genre", "decade", "mood", "energy", "popularity"}
+
+// criteriaRecord records the discover criteria that were given
+func criteriaRecord(criteria moodify.Criteria) map[string]string {
+	values := []string{criteria.Preset, criteria.Genre, criteria.Decade, criteria.Mood, criteria.Energy, criteria.Popularity}
+	recorded := map[string]string{}
+	for i, key := range criteriaKeys {
+		if values[i] != "" {
+			recorded[key] = values[i]
+		}
+	}
+	return recorded
+}
+
+// criteriaFromRecord is the inverse of criteriaRecord
+func criteriaFromRecord(recorded map[string]string) moodify.Criteria {
+	return moodify.Criteria{
+		Genre:      recorded["genre"],
+		Decade:     recorded["decade"],
+		Mood:       recorded["mood"],
+		Energy:     recorded["energy"],
+		Popularity: recorded["popularity"],
+		Preset:     recorded["preset"],
+	}
+}
+
+// seedsRecord records recommendation seeds
+func seedsRecord(seeds spotify.Seeds) history.Seeds {
+	recorded := history.Seeds{Genres: seeds.Genres}
+	for _, id := range seeds.Artists {
+		recorded.Artists = append(recorded.Artists, string(id))
+	}
+	for _, id := range seeds.Tracks {
+		recorded.Tracks = append(recorded.Tracks, string(id))
+	}
+	return recorded
+}
+
+// seedsFromRecord is the inverse of seedsRecord, or nil when nothing was recorded
+func seedsFromRecord(recorded history.Seeds) *spotify.Seeds {
+	if recorded.Empty() {
+		return nil
+	}
+	seeds := recorded.Spotify()
+	return &seeds
+}
+
+// recordedTrackIDs returns the IDs of the tracks a search found
+func recordedTrackIDs(search *history.Search) []spotify.ID {
+	ids := make([]spotify.ID, 0, len(search.TrackIDs))
+	for _, id := range search.TrackIDs {
+		ids = append(ids, spotify.ID(id))
+	}
+	return ids
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lorrehuggan/moodify/internal/sequence"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+// sequenceHarmonic is the --sequence value that orders search results for mixing
+const sequenceHarmonic = "harmonic"
+
+var (
+	sequenceMethod  string
+	sequenceWeights sequence.Weights
+	sequenceApply   bool
+	sequenceSaveAs  string
+	sequencePublic  bool
+)
+
+func init() {
+	sequenceCmd := withScopes(&cobra.Command{
+		Use:   "sequence <playlist>",
+		Short: "Reorder a playlist for smooth, DJ-style transitions",
+		Long: `Reorder a playlist so each track flows into the next.
+
+Transitions are scored on Camelot-wheel key compatibility, tempo proximity
+(half and double time count as a match) and energy flow. The weights of each
+part are configurable, and the order is found with a greedy nearest-neighbour
+pass optionally refined by 2-opt.
+
+By default the new order and a transition report are only printed. Use --apply
+to reorder the playlist itself, or --save to write the result to a new playlist.
+
+Examples:
+  moodify sequence "Friday Mix"
+  moodify sequence "Friday Mix" --apply
+  moodify sequence "Friday Mix" --save "Friday Mix (DJ)" --energy-weight 1`,
+		Args: cobra.ExactArgs(1),
+		RunE: runSequence,
+	}, playlistCreateScopes...)
+
+	sequenceCmd.Flags().StringVar(&sequenceMethod, "method", sequence.MethodTwoOpt, "Ordering method: greedy or 2opt")
+	sequenceCmd.Flags().Float64Var(&sequenceWeights.Key, "key-weight", sequence.DefaultWeights.Key, "Weight of key compatibility")
+	sequenceCmd.Flags().Float64Var(&sequenceWeights.Tempo, "tempo-weight", sequence.DefaultWeights.Tempo, "Weight of tempo proximity")
+	sequenceCmd.Flags().Float64Var(&sequenceWeights.Energy, "energy-weight", sequence.DefaultWeights.Energy, "Weight of energy flow")
+	sequenceCmd.Flags().BoolVar(&sequenceApply, "apply", false, "Reorder the playlist itself")
+	sequenceCmd.Flags().StringVar(&sequenceSaveAs, "save", "", "Save the sequenced tracks to a new playlist with this name")
+	sequenceCmd.Flags().BoolVar(&sequencePublic, "public", false, "Make the new playlist public (with --save)")
+	sequenceCmd.MarkFlagsMutuallyExclusive("apply", "save")
+
+	rootCmd.AddCommand(sequenceCmd)
+}
+
+func runSequence(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if sequenceWeights.Key < 0 || sequenceWeights.Tempo < 0 || sequenceWeights.Energy < 0 {
+		return fmt.Errorf("weights can't be negative")
+	}
+
+	client, playlist, err := resolvePlaylistArg(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	full, total, err := spotifyx.PlaylistTracksWithTotal(ctx, client, playlist.ID)
+	if err != nil {
+		return err
+	}
+	if len(full) < 3 {
+		fmt.Println("👌 Nothing to sequence, the playlist has fewer than 3 tracks")
+		return nil
+	}
+
+	tracks := make([]spotify.SimpleTrack, 0, len(full))
+	for _, track := range full {
+		tracks = append(tracks, track.SimpleTrack)
+	}
+
+	fmt.Printf("🎛️  Sequencing %d tracks from \"%s\"...\n", len(tracks), playlist.Name)
+	fmt.Println()
+
+	ordered, result, err := sequenceTracks(ctx, client, tracks, sequence.Options{
+		Method:  sequenceMethod,
+		Weights: sequenceWeights,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, t := range ordered {
+		fmt.Printf("%3d. %s\n", i+1, formatTrackLabel(t))
+	}
+	fmt.Println()
+	printTransitionReport(result)
+
+	ids := make([]spotify.ID, 0, len(ordered))
+	for _, t := range ordered {
+		ids = append(ids, t.ID)
+	}
+
+	switch {
+	case sequenceApply:
+		// Replacing the tracks would lose anything that wasn't fetched or sequenced
+		if skipped := total - len(tracks); skipped > 0 {
+			return fmt.Errorf("the playlist has %d local files or episodes that would be lost; use --save to write a new playlist instead", skipped)
+		}
+		if total != len(ids) {
+			return fmt.Errorf("the playlist changed while it was being sequenced; run the command again")
+		}
+		if err := replacePlaylistTracks(ctx, client, playlist.ID, ids); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Reordered \"%s\"\n", playlist.Name)
+
+	case sequenceSaveAs != "":
+		if _, err := newService(client).SavePlaylist(ctx, sequenceSaveAs, ordered, moodify.SavePlaylistOptions{Public: sequencePublic}); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Saved the sequence to \"%s\"\n", sequenceSaveAs)
+
+	default:
+		fmt.Println("💡 Tips:")
+		fmt.Printf("   • Reorder the playlist: moodify sequence %q --apply\n", args[0])
+		fmt.Printf("   • Or keep the original: moodify sequence %q --save \"%s (DJ)\"\n", args[0], playlist.Name)
+	}
+
+	return nil
+}
+
+// sequenceTracks fetches audio features and orders tracks for smooth
+// transitions, returning the reordered tracks and the sequencing result
+func sequenceTracks(ctx context.Context, client *spotify.Client, tracks []spotify.SimpleTrack, opts sequence.Options) ([]spotify.SimpleTrack, *sequence.Result, error) {
+	ids := make([]spotify.ID, 0, len(tracks))
+	for _, t := range tracks {
+		ids = append(ids, t.ID)
+	}
+
+	features, err := spotifyx.GetAudioFeaturesBatch(ctx, client, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sequencing needs audio features: %w", err)
+	}
+
+	input := make([]sequence.Track, 0, len(tracks))
+	for i, t := range tracks {
+		artist := "Unknown Artist"
+		if len(t.Artists) > 0 {
+			artist = t.Artists[0].Name
+		}
+		input = append(input, sequence.Track{Index: i, Name: t.Name, Artist: artist, Features: features[t.ID]})
+	}
+
+	result, err := sequence.Sequence(input, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ordered := make([]spotify.SimpleTrack, 0, len(tracks))
+	for _, t := range result.Tracks {
+		ordered = append(ordered, tracks[t.Index])
+	}
+	return ordered, result, nil
+}
+
+// printTransitionReport lists every transition with its key, tempo and energy change
+func printTransitionReport(result *sequence.Result) {
+	fmt.Println("🔀 Transitions")
+	counts := map[string]int{}
+	for _, t := range result.Transitions {
+		counts[t.Quality]++
+		fmt.Printf("   %s %s → %s\n", qualityIcon(t.Quality), truncateText(32, t.From.Name), truncateText(32, t.To.Name))
+		fmt.Printf("      %s → %s • %.0f → %.0f BPM • energy %+.2f\n",
+			t.FromKey, t.ToKey, t.FromTempo, t.ToTempo, t.EnergyDelta)
+	}
+	fmt.Println()
+
+	fmt.Printf("📊 %d smooth • %d good • %d rough • %d clashing transitions\n",
+		counts["smooth"], counts["good"], counts["rough"], counts["clash"])
+	if result.OriginalCost > 0 {
+		improvement := (1 - result.TotalCost/result.OriginalCost) * 100
+		fmt.Printf("   Transition cost %.2f → %.2f (%.0f%% smoother than the original order)\n",
+			result.OriginalCost, result.TotalCost, improvement)
+	}
+	if result.Unsequenced > 0 {
+		fmt.Printf("   ⚠️  %d tracks without audio features were placed at the end\n", result.Unsequenced)
+	}
+	fmt.Println()
+}
+
+// qualityIcon returns the marker shown for a transition quality
+func qualityIcon(quality string) string {
+	switch quality {
+	case "smooth":
+		return "🟢"
+	case "good":
+		return "🟡"
+	case "rough":
+		return "🟠"
+	default:
+		return "🔴"
+	}
+}
+
+// replacePlaylistTracks overwrites a playlist's tracks, 100 at a time
+func replacePlaylistTracks(ctx context.Context, client *spotify.Client, playlistID spotify.ID, ids []spotify.ID) error {
+	first := ids
+	if len(first) > 100 {
+		first = first[:100]
+	}
+	if err := client.ReplacePlaylistTracks(ctx, playlistID, first...); err != nil {
+		return fmt.Errorf("failed to reorder playlist: %w", err)
+	}
+	return spotifyx.AddTracksInBatches(ctx, client, playlistID, ids[len(first):])
+}
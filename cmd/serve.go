@@ -0,0 +1,496 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/auth"
+	"github.com/lorrehuggan/moodify/internal/config"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/lorrehuggan/moodify/internal/server"
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+// serveTokenFile stores the generated bearer token between runs
+const serveTokenFile = "serve-token"
+
+// serveTokenEnv overrides the bearer token without putting it on the command line
+const serveTokenEnv = "MOODIFY_SERVE_TOKEN"
+
+// serveRefreshInterval is how often the Spotify token is checked for refresh
+const serveRefreshInterval = time.Minute
+
+var (
+	serveAddr      string
+	serveToken     string
+	serveLogFormat string
+)
+
+func init() {
+	serveCmd := unlimitedRequests(&cobra.Command{
+		Use:   "serve",
+		Short: "Run a local HTTP API for scripts and other apps",
+		Long: `Serve moodify's features as a local REST API, using the token from 'moodify login'
+and refreshing it in the background.
+
+Responses use the same JSON as --output json on the matching commands:
+  GET  /health                 Server and token status (no bearer token needed)
+  GET  /parse?q=...            Parse a vibe into filters
+  GET  /search?q=...           Like 'moodify search' (limit, duration, tolerance, fit)
+  GET  /discover               Like 'moodify discover' (genre, decade, mood, energy,
+                               popularity, preset, limit, duration, tolerance, fit)
+  GET  /now                    Like 'moodify now' (features=true adds audio features)
+  POST /playback               {"action": "play|pause|next|previous|queue", "tracks": [...]}
+  GET  /playlists              Like 'moodify playlists' (public, private, all, limit, all_pages)
+  POST /playlists              {"name": "...", "public": false, "tracks": [...]}
+
+Every other request needs an "Authorization: Bearer <token>" header. The token is
+taken from --token or MOODIFY_SERVE_TOKEN, or generated once and kept in the
+moodify config directory.
+
+Examples:
+  moodify serve
+  moodify serve --addr 127.0.0.1:9000 --log-format json
+  curl -H "Authorization: Bearer $(cat ~/.config/moodify/serve-token)" \
+    "http://127.0.0.1:7777/search?q=chill+indie&limit=10"`,
+		RunE: runServe,
+	})
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:7777", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Bearer token callers must send (default: $MOODIFY_SERVE_TOKEN or a generated one)")
+	serveCmd.Flags().StringVar(&serveLogFormat, "log-format", "text", "Request log format on stderr: text or json")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	var logger *slog.Logger
+	switch serveLogFormat {
+	case "text":
+		logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	default:
+		return fmt.Errorf("unknown --log-format %q (use text or json)", serveLogFormat)
+	}
+	// serve refreshes its own token, so it declares no scopes for the shared client
+	tokens, err := auth.NewTokenSource(authConfig(auth.DefaultScopes))
+	if err != nil {
+		return err
+	}
+	if _, err := tokens.Token(); err != nil {
+		return err
+	}
+
+	token, tokenSource, err := resolveServeToken()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go tokens.KeepFresh(ctx, serveRefreshInterval, func(err error) {
+		logger.Error("token refresh failed", slog.String("error", err.Error()))
+	})
+
+	api := &apiServer{svc: newService(tokens.Client(ctx)), tokens: tokens}
+	srv := &http.Server{
+		Addr:              serveAddr,
+		Handler:           server.LogRequests(logger, api.routes(token)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveAddr, err)
+	}
+
+	fmt.Printf("🌐 Moodify API listening on http://%s\n", listener.Addr())
+	// Only say where the token is, so it stays out of scrollback and service logs
+	fmt.Printf("🔑 Bearer token %s\n", tokenSource)
+	if !isLoopbackAddr(serveAddr) {
+		fmt.Println("⚠️  Listening beyond this machine - anyone who can reach it and has the token controls your Spotify")
+	}
+	fmt.Println("   Press Ctrl-C to stop")
+
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Serve(listener) }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Println("\n👋 Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// resolveServeToken picks the bearer token and describes where it came from
+func resolveServeToken() (token, source string, err error) {
+	if serveToken != "" {
+		return serveToken, "from --token", nil
+	}
+	if env := os.Getenv(serveTokenEnv); env != "" {
+		return env, "from " + serveTokenEnv, nil
+	}
+
+	path, err := config.Path(serveTokenFile)
+	if err != nil {
+		return "", "", err
+	}
+	if data, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), "in " + path, nil
+	}
+
+	token, err = server.GenerateToken()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", "", fmt.Errorf("failed to save token: %w", err)
+	}
+	return token, "generated and saved in " + path, nil
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local connections
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// apiServer handles the `moodify serve` endpoints
+type apiServer struct {
+	svc    *moodify.Service
+	tokens *auth.TokenSource
+}
+
+// routes returns the API, with everything but /health behind the bearer token
+func (s *apiServer) routes(token string) http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("GET /parse", s.handleParse)
+	api.HandleFunc("GET /search", s.handleSearch)
+	api.HandleFunc("GET /discover", s.handleDiscover)
+	api.HandleFunc("GET /now", s.handleNow)
+	api.HandleFunc("POST /playback", s.handlePlayback)
+	api.HandleFunc("GET /playlists", s.handlePlaylists)
+	api.HandleFunc("POST /playlists", s.handleCreatePlaylist)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.Handle("/", server.RequireToken(token, api))
+	return mux
+}
+
+// healthResult is the response of GET /health
+type healthResult struct {
+	Status      string    `json:"status"`
+	TokenExpiry time.Time `json:"token_expiry"`
+}
+
+func (s *apiServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	server.WriteJSON(w, http.StatusOK, healthResult{Status: "ok", TokenExpiry: s.tokens.Expiry()})
+}
+
+func (s *apiServer) handleParse(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		server.WriteError(w, http.StatusBadRequest, "missing q parameter")
+		return
+	}
+
+	server.WriteJSON(w, http.StatusOK, moodify.Parse(r.Context(), query))
+}
+
+func (s *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		server.WriteError(w, http.StatusBadRequest, "missing q parameter")
+		return
+	}
+	count, err := queryInt(r, "limit", 15, 1, 100)
+	if err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts, err := queryFitOptions(r)
+	if err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.svc.Search(r.Context(), query, moodify.SearchOptions{Limit: count, Fit: opts})
+	if err != nil {
+		server.WriteFailure(w, spotifyStatus(err), err)
+		return
+	}
+	server.WriteJSON(w, http.StatusOK, result)
+}
+
+func (s *apiServer) handleDiscover(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	criteria := moodify.Criteria{
+		Genre:      values.Get("genre"),
+		Decade:     values.Get("decade"),
+		Mood:       values.Get("mood"),
+		Energy:     values.Get("energy"),
+		Popularity: values.Get("popularity"),
+		Preset:     values.Get("preset"),
+	}
+	count, err := queryInt(r, "limit", 20, 1, 50)
+	if err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts, err := queryFitOptions(r)
+	if err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	found, err := s.svc.Discover(r.Context(), moodify.DiscoverOptions{Criteria: criteria, Limit: count, Fit: opts})
+	if err != nil {
+		server.WriteFailure(w, spotifyStatus(err), err)
+		return
+	}
+	server.WriteJSON(w, http.StatusOK, found)
+}
+
+func (s *apiServer) handleNow(w http.ResponseWriter, r *http.Request) {
+	features, err := queryBool(r, "features")
+	if err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	np, err := s.svc.NowPlaying(r.Context(), features)
+	if err != nil {
+		server.WriteFailure(w, spotifyStatus(err), fmt.Errorf("failed to get currently playing track: %w", err))
+		return
+	}
+	server.WriteJSON(w, http.StatusOK, np)
+}
+
+// playbackRequest is the body of POST /playback
+type playbackRequest struct {
+	Action string   `json:"action"`
+	Tracks []string `json:"tracks"` // track IDs, URIs or links
+}
+
+// playbackResult is the response of POST /playback
+type playbackResult struct {
+	Action string `json:"action"`
+	Tracks int    `json:"tracks"`
+}
+
+func (s *apiServer) handlePlayback(w http.ResponseWriter, r *http.Request) {
+	var req playbackRequest
+	if err := server.DecodeJSON(r, w, &req); err != nil {
+		server.WriteError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	tracks, err := requestTracks(req.Tracks)
+	if err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	client := s.svc.Client()
+	result := playbackResult{Action: req.Action}
+	switch req.Action {
+	case "play":
+		if len(tracks) > 0 {
+			err = s.svc.Play(ctx, tracks)
+			result.Tracks = len(tracks)
+		} else {
+			err = client.Play(ctx)
+		}
+	case "pause":
+		err = client.Pause(ctx)
+	case "next":
+		err = client.Next(ctx)
+	case "previous":
+		err = client.Previous(ctx)
+	case "queue":
+		if len(tracks) == 0 {
+			server.WriteError(w, http.StatusBadRequest, "queue needs at least one track")
+			return
+		}
+		result.Tracks, err = s.svc.Queue(ctx, tracks)
+	default:
+		server.WriteError(w, http.StatusBadRequest, fmt.Sprintf("unknown action %q (use play, pause, next, previous or queue)", req.Action))
+		return
+	}
+
+	if err != nil {
+		err = errs.ClassifyPlayback(err)
+		server.WriteFailure(w, spotifyStatus(err), err)
+		return
+	}
+	server.WriteJSON(w, http.StatusOK, result)
+}
+
+func (s *apiServer) handlePlaylists(w http.ResponseWriter, r *http.Request) {
+	opts := moodify.PlaylistOptions{}
+	var err error
+	for name, target := range map[string]*bool{
+		"public":    &opts.Public,
+		"private":   &opts.Private,
+		"all":       &opts.All,
+		"all_pages": &opts.Every,
+	} {
+		if *target, err = queryBool(r, name); err != nil {
+			server.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if opts.Limit, err = queryInt(r, "limit", 20, 1, 1000); err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	listing, err := s.svc.Playlists(r.Context(), opts)
+	if err != nil {
+		server.WriteFailure(w, spotifyStatus(err), err)
+		return
+	}
+	server.WriteJSON(w, http.StatusOK, listing)
+}
+
+// createPlaylistRequest is the body of POST /playlists
+type createPlaylistRequest struct {
+	Name   string   `json:"name"`
+	Public bool     `json:"public"`
+	Tracks []string `json:"tracks"` // track IDs, URIs or links
+}
+
+func (s *apiServer) handleCreatePlaylist(w http.ResponseWriter, r *http.Request) {
+	var req createPlaylistRequest
+	if err := server.DecodeJSON(r, w, &req); err != nil {
+		server.WriteError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		server.WriteError(w, http.StatusBadRequest, "missing playlist name")
+		return
+	}
+	tracks, err := requestTracks(req.Tracks)
+	if err != nil {
+		server.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(tracks) == 0 {
+		server.WriteError(w, http.StatusBadRequest, "a playlist needs at least one track")
+		return
+	}
+
+	playlist, err := s.svc.SavePlaylist(r.Context(), req.Name, tracks, moodify.SavePlaylistOptions{Public: req.Public})
+	if err != nil {
+		server.WriteFailure(w, spotifyStatus(err), err)
+		return
+	}
+	server.WriteJSON(w, http.StatusCreated, playlist)
+}
+
+// requestTracks turns track IDs, URIs or links from a request into tracks
+func requestTracks(refs []string) ([]spotify.SimpleTrack, error) {
+	ids, ok := parseTrackIDs(refs)
+	if !ok {
+		return nil, fmt.Errorf("tracks must be Spotify track IDs, URIs or links")
+	}
+	tracks := make([]spotify.SimpleTrack, 0, len(ids))
+	for _, id := range ids {
+		tracks = append(tracks, spotify.SimpleTrack{ID: id, URI: spotify.URI("spotify:track:" + id)})
+	}
+	return tracks, nil
+}
+
+// queryInt reads an integer query parameter within [min, max]
+func queryInt(r *http.Request, name string, def, lo, hi int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < lo || value > hi {
+		return 0, fmt.Errorf("%s must be a number between %d and %d", name, lo, hi)
+	}
+	return value, nil
+}
+
+// queryBool reads a boolean query parameter, false when absent
+func queryBool(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return value, nil
+}
+
+// queryFitOptions reads the duration, tolerance and fit query parameters,
+// which work like the --duration flags
+func queryFitOptions(r *http.Request) (moodify.Fit, error) {
+	values := r.URL.Query()
+	opts := moodify.Fit{Tolerance: 90 * time.Second, Method: moodify.BestFit}
+
+	var err error
+	if raw := values.Get("duration"); raw != "" {
+		if opts.Target, err = time.ParseDuration(raw); err != nil || opts.Target < 0 {
+			return opts, fmt.Errorf("duration must be a positive duration such as 35m")
+		}
+	}
+	if raw := values.Get("tolerance"); raw != "" {
+		if opts.Tolerance, err = time.ParseDuration(raw); err != nil || opts.Tolerance < 0 {
+			return opts, fmt.Errorf("tolerance must be a positive duration such as 90s")
+		}
+	}
+	if raw := values.Get("fit"); raw != "" {
+		if raw != moodify.BestFit && raw != moodify.FirstFit {
+			return opts, fmt.Errorf("fit must be %s or %s", moodify.BestFit, moodify.FirstFit)
+		}
+		opts.Method = raw
+	}
+	return opts, nil
+}
+
+// spotifyStatus picks the response status for a failed Spotify call: failures
+// that callers can act on get a matching client error, anything else is a 502
+func spotifyStatus(err error) int {
+	if kind, ok := errs.KindOf(err); ok {
+		switch kind.Err {
+		case errs.ErrMissingScope, errs.ErrPremiumRequired:
+			return http.StatusForbidden
+		case errs.ErrNotFound, errs.ErrNoActiveDevice:
+			return http.StatusNotFound
+		case errs.ErrRateLimited:
+			return http.StatusTooManyRequests
+		}
+	}
+	return http.StatusBadGateway
+}
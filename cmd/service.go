@@ -0,0 +1,11 @@
+package cmd
+
+import (
+	"github.com/lorrehuggan/moodify/pkg/moodify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// newService wraps a client in the library the commands are built on
+func newService(client *spotify.Client) *moodify.Service {
+	return moodify.New(client, moodify.WithMarket(market))
+}
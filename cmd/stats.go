@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lorrehuggan/moodify/internal/history"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/lorrehuggan/moodify/internal/stats"
+	"github.com/spf13/cobra"
+	"github.com/zmb3/spotify/v2"
+)
+
+var statsLimit int
+
+// statsRanges are the Spotify time ranges compared by `moodify stats`, newest first
+var statsRanges = []struct {
+	Range spotify.Range
+	Label string
+}{
+	{spotify.ShortTermRange, "Last 4 weeks"},
+	{spotify.MediumTermRange, "Last 6 months"},
+	{spotify.LongTermRange, "All time"},
+}
+
+// statsTopItems is how many top artists and tracks are fetched per time range
+const statsTopItems = 100
+
+// historyDiscoveryWindow is how far back history-based discovery looks for new artists
+const historyDiscoveryWindow = 30 * 24 * time.Hour
+
+func init() {
+	statsCmd := withScopes(&cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics about your listening taste",
+		Long: `Analyze your top artists and tracks over the last 4 weeks, 6 months and all time.
+
+Reports your top genres, average audio-feature profile, favourite decades, how many
+of your recent artists are new to you, and which artists are trending up or down.
+If you record listening history with 'moodify history record', it is included too.
+
+Use --output json for machine-readable output.`,
+		RunE: runStats,
+	}, statsScopes...)
+
+	statsCmd.Flags().IntVarP(&statsLimit, "limit", "n", 10, "Number of entries to show in each section")
+	addOutputFlag(statsCmd)
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	client := spotifyClient(cmd)
+
+	if statsLimit < 1 {
+		statsLimit = 10
+	}
+
+	if !jsonOutput() {
+		fmt.Println("📊 Crunching your listening stats...")
+		fmt.Println()
+	}
+
+	report, err := buildStatsReport(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(report)
+	}
+
+	printStatsReport(report)
+	return nil
+}
+
+// buildStatsReport fetches top items for every time range and combines them
+// with local history into a report
+func buildStatsReport(ctx context.Context, client *spotify.Client) (*stats.Report, error) {
+	report := &stats.Report{
+		GeneratedAt: time.Now().UTC(),
+		TopArtists:  map[string][]string{},
+		TopTracks:   map[string][]string{},
+	}
+
+	artistsByRange := make([][]spotify.FullArtist, len(statsRanges))
+	var allTracks []spotify.FullTrack
+	seenTracks := map[spotify.ID]bool{}
+
+	for i, r := range statsRanges {
+		artists, err := spotifyx.TopArtists(ctx, client, r.Range, statsTopItems)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get top artists (%s): %w", r.Range, err)
+		}
+		artistsByRange[i] = artists
+
+		tracks, err := spotifyx.TopTracks(ctx, client, r.Range, statsTopItems)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get top tracks (%s): %w", r.Range, err)
+		}
+
+		for _, artist := range artists {
+			report.TopArtists[string(r.Range)] = append(report.TopArtists[string(r.Range)], artist.Name)
+		}
+		for _, track := range tracks {
+			report.TopTracks[string(r.Range)] = append(report.TopTracks[string(r.Range)], formatTrackLabel(track.SimpleTrack))
+			if !seenTracks[track.ID] {
+				seenTracks[track.ID] = true
+				allTracks = append(allTracks, track)
+			}
+		}
+	}
+
+	short, long := artistsByRange[0], artistsByRange[len(artistsByRange)-1]
+
+	report.TopGenres = stats.TopGenres(artistsByRange, statsLimit)
+	report.Decades = stats.Decades(allTracks)
+	report.Discovery = append(report.Discovery, stats.TopArtistDiscovery(short, long))
+	report.TrendingUp, report.TrendingDown = stats.Trends(long, short, statsLimit)
+
+	// Audio features are a nice-to-have; the endpoint isn't available to every app
+	ids := make([]spotify.ID, 0, len(allTracks))
+	for _, track := range allTracks {
+		ids = append(ids, track.ID)
+	}
+	if features, err := spotifyx.GetAudioFeaturesBatch(ctx, client, ids); err == nil {
+		report.Features = stats.AverageFeatures(features)
+	}
+
+	// Include local history if the user has been recording it
+	if store, err := history.Open(); err == nil {
+		if plays, err := store.Query(history.Query{}); err == nil && len(plays) > 0 {
+			report.History = stats.SummarizeHistory(plays, statsLimit)
+			report.Discovery = append(report.Discovery, stats.HistoryDiscovery(plays, time.Now(), historyDiscoveryWindow))
+		}
+	}
+
+	return report, nil
+}
+
+// printStatsReport renders the report as text tables and bar charts
+func printStatsReport(report *stats.Report) {
+	fmt.Println("📊 Your Listening Stats")
+	fmt.Println("═══════════════════════")
+	fmt.Println()
+
+	// Top artists side by side for each time range
+	fmt.Println("🏆 Top Artists")
+	const columnWidth = 26
+	header := ""
+	for _, r := range statsRanges {
+		header += padRight(r.Label, columnWidth)
+	}
+	fmt.Printf("   %s\n", strings.TrimRight(header, " "))
+	for i := 0; i < statsLimit; i++ {
+		row := ""
+		empty := true
+		for _, r := range statsRanges {
+			cell := ""
+			if names := report.TopArtists[string(r.Range)]; i < len(names) {
+				cell = fmt.Sprintf("%2d. %s", i+1, truncateText(columnWidth-6, names[i]))
+				empty = false
+			}
+			row += padRight(cell, columnWidth)
+		}
+		if empty {
+			break
+		}
+		fmt.Printf("   %s\n", strings.TrimRight(row, " "))
+	}
+	fmt.Println()
+
+	if len(report.TopGenres) > 0 {
+		fmt.Println("🎸 Top Genres")
+		rows := make([]barChartRow, 0, len(report.TopGenres))
+		for _, genre := range report.TopGenres {
+			rows = append(rows, barChartRow{Label: genre.Label, Value: genre.Value, Display: fmt.Sprintf("%.1f", genre.Value)})
+		}
+		printBarChart(rows, 0, 24)
+		fmt.Println()
+	}
+
+	if f := report.Features; f != nil {
+		fmt.Printf("🎛️  Audio Profile (%d tracks)\n", f.Tracks)
+		printBarChart([]barChartRow{
+			{Label: "Energy", Value: f.Energy, Display: fmt.Sprintf("%.2f", f.Energy)},
+			{Label: "Valence", Value: f.Valence, Display: fmt.Sprintf("%.2f", f.Valence)},
+			{Label: "Danceability", Value: f.Danceability, Display: fmt.Sprintf("%.2f", f.Danceability)},
+			{Label: "Acousticness", Value: f.Acousticness, Display: fmt.Sprintf("%.2f", f.Acousticness)},
+			{Label: "Instrumentalness", Value: f.Instrumentalness, Display: fmt.Sprintf("%.2f", f.Instrumentalness)},
+			{Label: "Speechiness", Value: f.Speechiness, Display: fmt.Sprintf("%.2f", f.Speechiness)},
+		}, 1.0, 24)
+		fmt.Printf("   Tempo: %.0f BPM • Loudness: %.1f dB\n", f.Tempo, f.Loudness)
+		fmt.Println()
+	}
+
+	if len(report.Decades) > 0 {
+		fmt.Println("📅 Decades")
+		rows := make([]barChartRow, 0, len(report.Decades))
+		for _, decade := range report.Decades {
+			rows = append(rows, barChartRow{Label: decade.Label, Value: decade.Value})
+		}
+		printBarChart(rows, 0, 24)
+		fmt.Println()
+	}
+
+	if len(report.Discovery) > 0 {
+		fmt.Println("🌱 Discovery")
+		for _, d := range report.Discovery {
+			switch d.Source {
+			case "history":
+				fmt.Printf("   %.0f%% of artists you played in the last 30 days were new to your history (%d of %d)\n",
+					d.Rate*100, d.NewArtists, d.Artists)
+			default:
+				fmt.Printf("   %.0f%% of your recent top artists aren't in your all-time top list (%d of %d)\n",
+					d.Rate*100, d.NewArtists, d.Artists)
+			}
+		}
+		fmt.Println()
+	}
+
+	printTrends("📈 Trending Up", report.TrendingUp)
+	printTrends("📉 Trending Down", report.TrendingDown)
+
+	if h := report.History; h != nil {
+		fmt.Println("📜 Recorded History")
+		since := h.FirstPlayAt
+		if t, err := time.Parse(time.RFC3339, h.FirstPlayAt); err == nil {
+			since = t.Local().Format("2006-01-02")
+		}
+		fmt.Printf("   %d plays • %s of listening • %d artists since %s\n",
+			h.Plays, formatListeningTime(time.Duration(h.ListenedMs)*time.Millisecond), h.Artists, since)
+		rows := make([]barChartRow, 0, len(h.TopArtists))
+		for _, artist := range h.TopArtists {
+			rows = append(rows, barChartRow{Label: artist.Label, Value: artist.Value})
+		}
+		printBarChart(rows, 0, 24)
+		fmt.Println()
+	}
+
+	fmt.Println("💡 Tips:")
+	fmt.Println("   • Record plays for richer stats: moodify history record")
+	fmt.Println("   • Export everything: moodify stats --output json")
+}
+
+// printTrends lists rank changes between the all-time and recent top artists
+func printTrends(title string, trends []stats.Trend) {
+	if len(trends) == 0 {
+		return
+	}
+
+	fmt.Println(title)
+	for _, t := range trends {
+		from, to := "new", "out"
+		if t.FromRank > 0 {
+			from = fmt.Sprintf("#%d", t.FromRank)
+		}
+		if t.ToRank > 0 {
+			to = fmt.Sprintf("#%d", t.ToRank)
+		}
+		fmt.Printf("   %s  %s → %s  (%+d)\n", padRight(truncateText(28, t.Artist), 28), from, to, t.Change)
+	}
+	fmt.Println()
+}
+
+// formatTrackLabel renders a track as "Track — Artist"
+func formatTrackLabel(track spotify.SimpleTrack) string {
+	artist := "Unknown Artist"
+	if len(track.Artists) > 0 {
+		artist = track.Artists[0].Name
+	}
+	return track.Name + " — " + artist
+}
+
+// padRight pads s with spaces to width characters
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
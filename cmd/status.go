@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -9,14 +10,22 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var statusCheck bool
+
 func init() {
 	statusCmd := &cobra.Command{
 		Use:   "status",
 		Short: "Check authentication status and configuration",
 		Long: `Display current authentication status, token expiry, and configuration details.
-Use this to verify your setup and troubleshoot authentication issues.`,
+Use this to verify your setup and troubleshoot authentication issues.
+
+An expired access token is fine as long as the refresh token works: commands
+refresh it when they need to. Use --check to refresh it now and confirm Spotify
+still accepts the refresh token; the command then fails if you need to log in
+again.`,
 		RunE: runStatus,
 	}
+	statusCmd.Flags().BoolVar(&statusCheck, "check", false, "Refresh the token with Spotify to check the login still works")
 
 	rootCmd.AddCommand(statusCmd)
 }
@@ -49,26 +58,49 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println()
 
-	// Check authentication status
+	// Check authentication status, refreshing first with --check
+	var checkErr error
+	if statusCheck {
+		_, checkErr = auth.RefreshNow(context.Background(), authConfig(auth.DefaultScopes))
+	}
+	token := auth.CheckToken()
+
 	fmt.Println("🔐 Authentication:")
-	if auth.QuickCheck() {
+	switch token.State {
+	case auth.TokenValid:
 		fmt.Println("   Status: ✅ Authenticated and ready")
-
-		// Try to get token details
-		if token, err := auth.LoadTokenForStatus(); err == nil {
-			timeUntilExpiry := time.Until(token.Expiry)
-			if timeUntilExpiry > 0 {
-				fmt.Printf("   Token expires: %s (%s from now)\n",
-					token.Expiry.Format("2006-01-02 15:04:05"),
-					formatDuration(timeUntilExpiry))
-			} else {
-				fmt.Println("   Token expires: ⚠️  Expired (will auto-refresh on next use)")
-			}
-		}
-	} else {
+		fmt.Printf("   Token expires: %s (%s from now)\n",
+			token.Expiry.Format("2006-01-02 15:04:05"),
+			formatDuration(time.Until(token.Expiry)))
+	case auth.TokenRefreshable:
+		fmt.Println("   Status: ✅ Authenticated")
+		fmt.Println("   Token expires: ⚠️  Expired or expiring (will auto-refresh on next use)")
+	case auth.TokenRefreshFailed:
+		fmt.Println("   Status: ❌ Login expired")
+		fmt.Printf("   Reason: %s\n", token.RefreshError)
+		fmt.Println("   Action: Run 'moodify login' to authenticate again")
+	default:
 		fmt.Println("   Status: ❌ Not authenticated")
 		fmt.Println("   Action: Run 'moodify login' to authenticate")
 	}
+
+	switch {
+	case token.State == auth.TokenMissing:
+	case !token.RefreshFailedAt.IsZero():
+		fmt.Printf("   Refresh token: ❌ Rejected by Spotify on %s\n", token.RefreshFailedAt.Format("2006-01-02 15:04:05"))
+	case token.HasRefreshToken:
+		fmt.Println("   Refresh token: ✅ Stored")
+	default:
+		fmt.Println("   Refresh token: ❌ Not stored")
+	}
+
+	if statusCheck {
+		if checkErr != nil {
+			fmt.Printf("   Live check: ❌ %v\n", checkErr)
+		} else {
+			fmt.Println("   Live check: ✅ Spotify accepted the refresh token")
+		}
+	}
 	fmt.Println()
 
 	// Check config directory
@@ -88,7 +120,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Show available commands based on status
 	fmt.Println("💡 Available Actions:")
-	if !auth.QuickCheck() {
+	if token.State != auth.TokenValid && token.State != auth.TokenRefreshable {
 		fmt.Println("   • moodify login     - Authenticate with Spotify")
 		fmt.Println("   • moodify setup     - Configure custom Spotify app (optional)")
 	} else {
@@ -96,7 +128,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		fmt.Println("   • moodify logout    - Remove stored credentials")
 	}
 
-	return nil
+	return checkErr
 }
 
 func formatDuration(d time.Duration) string {
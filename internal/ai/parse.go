@@ -2,22 +2,32 @@ package ai
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/lorrehuggan/moodify/internal/errs"
 	openai "github.com/sashabaranov/go-openai"
 )
 
 type Filters struct {
-	Genres                           []string
-	MinDanceability, MaxDanceability float64
-	MinEnergy, MaxEnergy             float64
-	MinValence, MaxValence           float64
-	MinTempo, MaxTempo               float64
-	MinPopularity, MaxPopularity     int
-	YearStart, YearEnd               int
+	Genres          []string `json:"genres"`
+	MinDanceability float64  `json:"min_danceability"`
+	MaxDanceability float64  `json:"max_danceability"`
+	MinEnergy       float64  `json:"min_energy"`
+	MaxEnergy       float64  `json:"max_energy"`
+	MinValence      float64  `json:"min_valence"`
+	MaxValence      float64  `json:"max_valence"`
+	MinTempo        float64  `json:"min_tempo"`
+	MaxTempo        float64  `json:"max_tempo"`
+	MinPopularity   int      `json:"min_popularity"`
+	MaxPopularity   int      `json:"max_popularity"`
+	YearStart       int      `json:"year_start"`
+	YearEnd         int      `json:"year_end"`
+	// ExcludeGenres are genres to filter out of results, e.g. after "no rap"
+	ExcludeGenres []string `json:"exclude_genres,omitempty"`
 }
 
 // ParseQuery: AI-powered parser (falls back to SimpleParse if no API key)
@@ -48,8 +58,11 @@ Prefer broad ranges if uncertain.`
 		Temperature: 0.2,
 	})
 	if err != nil || len(resp.Choices) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no response from the model")
+		}
 		// Return the error so calling code can show appropriate fallback message
-		return SimpleParse(q), err
+		return SimpleParse(q), errs.Wrap(errs.ErrAIUnavailable, err)
 	}
 
 	jsonText := strings.TrimSpace(resp.Choices[0].Message.Content)
@@ -77,6 +90,56 @@ Prefer broad ranges if uncertain.`
 	return f, nil
 }
 
+// Check confirms the OpenAI API key works, without spending any tokens
+func Check(ctx context.Context) error {
+	key := os.Getenv("OPENAI_API_KEY")
+	if key == "" {
+		return errs.Wrap(errs.ErrAIUnavailable, fmt.Errorf("OPENAI_API_KEY is not set"))
+	}
+	if _, err := openai.NewClient(key).ListModels(ctx); err != nil {
+		return errs.Wrap(errs.ErrAIUnavailable, err)
+	}
+	return nil
+}
+
+// genreKeywords maps words in a prompt to valid Spotify recommendation genres
+var genreKeywords = map[string]string{
+	"indie":         "indie",
+	"pop":           "pop",
+	"rock":          "rock",
+	"jazz":          "jazz",
+	"house":         "house",
+	"techno":        "techno",
+	"classical":     "classical",
+	"ambient":       "ambient",
+	"electronic":    "electronic",
+	"hip-hop":       "hip-hop",
+	"hip hop":       "hip-hop",
+	"country":       "country",
+	"folk":          "folk",
+	"blues":         "blues",
+	"reggae":        "reggae",
+	"metal":         "metal",
+	"punk":          "punk",
+	"alternative":   "alternative",
+	"r&b":           "r-n-b",
+	"rnb":           "r-n-b",
+	"soul":          "soul",
+	"funk":          "funk",
+	"disco":         "disco",
+	"dance":         "dance",
+	"edm":           "edm",
+	"dubstep":       "dubstep",
+	"drum-and-bass": "drum-and-bass",
+	"dnb":           "drum-and-bass",
+	"trance":        "trance",
+	"garage":        "garage",
+	"ska":           "ska",
+	"gospel":        "gospel",
+	"latin":         "latin",
+	"world":         "world-music",
+}
+
 // SimpleParse: tiny heuristic fallback
 func SimpleParse(q string) Filters {
 	q = strings.ToLower(q)
@@ -112,45 +175,9 @@ func SimpleParse(q string) Filters {
 	if strings.Contains(q, "2000s") {
 		f.YearStart, f.YearEnd = 2000, 2009
 	}
-	// common genres (using valid Spotify recommendation genres)
-	genreMap := map[string]string{
-		"indie":         "indie",
-		"pop":           "pop",
-		"rock":          "rock",
-		"jazz":          "jazz",
-		"house":         "house",
-		"techno":        "techno",
-		"classical":     "classical",
-		"ambient":       "ambient",
-		"electronic":    "electronic",
-		"hip-hop":       "hip-hop",
-		"hip hop":       "hip-hop",
-		"country":       "country",
-		"folk":          "folk",
-		"blues":         "blues",
-		"reggae":        "reggae",
-		"metal":         "metal",
-		"punk":          "punk",
-		"alternative":   "alternative",
-		"r&b":           "r-n-b",
-		"rnb":           "r-n-b",
-		"soul":          "soul",
-		"funk":          "funk",
-		"disco":         "disco",
-		"dance":         "dance",
-		"edm":           "edm",
-		"dubstep":       "dubstep",
-		"drum-and-bass": "drum-and-bass",
-		"dnb":           "drum-and-bass",
-		"trance":        "trance",
-		"garage":        "garage",
-		"ska":           "ska",
-		"gospel":        "gospel",
-		"latin":         "latin",
-		"world":         "world-music",
-	}
-
-	for keyword, genre := range genreMap {
+
+	// common genres
+	for keyword, genre := range genreKeywords {
 		if strings.Contains(q, keyword) {
 			f.Genres = append(f.Genres, genre)
 		}
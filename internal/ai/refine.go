@@ -0,0 +1,345 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ErrNoChange is returned by Refine when a message doesn't describe any
+// change to the filters
+var ErrNoChange = errors.New("no change to the filters recognised")
+
+// step is how far a relative modifier like "more upbeat" moves a 0-1 attribute
+const step = 0.2
+
+var (
+	excludePattern = regexp.MustCompile(`\b(?:no|without|less|not|skip|drop)\s+(?:(?:more|any)\s+)?([a-z&\- ]+)`)
+	includePattern = regexp.MustCompile(`\b(?:more|add|with|some|plus)(?:\s+(?:some|more|a\s+bit\s+of|a\s+little))*\s+([a-z&\- ]+)`)
+	onlyPattern    = regexp.MustCompile(`\b(?:only|just)\s+([a-z&\- ]+)`)
+	// A decade as "1990s", "2000s", "90s" or "90's"
+	decadePattern = regexp.MustCompile(`\b((?:19|20)[0-9]0|[0-9]0)'?s\b`)
+)
+
+// excludableGenres are genre words that aren't recommendation genres but can
+// still be matched against artist genres to filter results, as in "no rap"
+var excludableGenres = []string{"rap", "trap", "drill", "emo", "grunge", "screamo", "k-pop", "christmas", "acoustic", "instrumental"}
+
+// Refine applies a follow-up message such as "more upbeat", "no rap" or
+// "older stuff" to the current filters. The LLM interprets the message when
+// OPENAI_API_KEY is set; otherwise, or if that fails, rule-based modifiers do.
+func Refine(ctx context.Context, current Filters, message string) (Filters, error) {
+	if os.Getenv("OPENAI_API_KEY") != "" {
+		if f, err := refineWithLLM(ctx, current, message); err == nil {
+			return f, nil
+		}
+	}
+
+	f, ok := RefineSimple(current, message)
+	if !ok {
+		return current, ErrNoChange
+	}
+	return f, nil
+}
+
+func refineWithLLM(ctx context.Context, current Filters, message string) (Filters, error) {
+	c := openai.NewClient(os.Getenv("OPENAI_API_KEY"))
+
+	state, err := json.Marshal(current)
+	if err != nil {
+		return current, err
+	}
+
+	sys := `You adjust Spotify Recommendations filters from a follow-up request about the current results.
+You get the current filters as JSON and the user's message. Return ONLY the complete updated JSON
+with the same fields, changing only what the message asks for:
+genres (array of lowercase strings, max 3), exclude_genres (genres to filter out),
+min_danceability, max_danceability, min_energy, max_energy, min_valence, max_valence (0..1),
+min_tempo, max_tempo (BPM, 0 for no limit), min_popularity, max_popularity (0..100),
+year_start, year_end (integers or 0).`
+
+	resp, err := c.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: "gpt-4o-mini",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: "system", Content: sys},
+			{Role: "user", Content: fmt.Sprintf("Current filters: %s\nMessage: %s", state, message)},
+		},
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return current, err
+	}
+	if len(resp.Choices) == 0 {
+		return current, fmt.Errorf("empty response")
+	}
+
+	text := strings.TrimSpace(resp.Choices[0].Message.Content)
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "```json"), "```")
+	text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+
+	var f Filters
+	if err := json.Unmarshal([]byte(text), &f); err != nil {
+		return current, fmt.Errorf("unexpected response: %w", err)
+	}
+	f.clamp()
+	if Equal(f, current) {
+		return current, ErrNoChange
+	}
+	return f, nil
+}
+
+// RefineSimple applies rule-based modifiers for common follow-ups. It reports
+// false when nothing in the message was recognised.
+func RefineSimple(current Filters, message string) (Filters, bool) {
+	q := strings.ToLower(message)
+	f := current
+	f.Genres = slices.Clone(current.Genres)
+	f.ExcludeGenres = slices.Clone(current.ExcludeGenres)
+
+	// Keywords are matched against whole words, so "pump" doesn't match "pumpkin"
+	padded := " " + strings.Join(strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	}), " ") + " "
+	has := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(padded, " "+w+" ") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Mood
+	if has("upbeat", "happier", "happy", "cheerful", "brighter", "positive") {
+		f.MinValence = math.Min(f.MinValence+step, 0.9)
+		f.MaxValence = math.Max(f.MaxValence, f.MinValence+0.1)
+	}
+	if has("sadder", "darker", "moodier", "melancholic", "gloomier") {
+		f.MaxValence = math.Max(f.MaxValence-step, 0.1)
+		f.MinValence = math.Min(f.MinValence, f.MaxValence-0.1)
+	}
+
+	// Energy
+	if has("more energy", "more energetic", "harder", "heavier", "intense", "louder", "pump") {
+		f.MinEnergy = math.Min(f.MinEnergy+step, 0.9)
+		f.MaxEnergy = math.Max(f.MaxEnergy, f.MinEnergy+0.1)
+	}
+	if has("calmer", "chiller", "mellower", "softer", "quieter", "less energy", "relaxed", "gentler") {
+		f.MaxEnergy = math.Max(f.MaxEnergy-step, 0.1)
+		f.MinEnergy = math.Min(f.MinEnergy, f.MaxEnergy-0.1)
+	}
+
+	// Danceability
+	if has("danceable", "groovier", "groovy", "dancier") {
+		f.MinDanceability = math.Min(f.MinDanceability+step, 0.9)
+		f.MaxDanceability = math.Max(f.MaxDanceability, f.MinDanceability+0.1)
+	}
+
+	// Tempo
+	if has("faster", "quicker", "speed up") {
+		if f.MinTempo == 0 {
+			f.MinTempo = 110
+		} else {
+			f.MinTempo += 15
+		}
+		if f.MaxTempo > 0 && f.MaxTempo < f.MinTempo+10 {
+			f.MaxTempo = f.MinTempo + 30
+		}
+	}
+	if has("slower", "slow it down", "slow down") {
+		if f.MaxTempo == 0 {
+			f.MaxTempo = 100
+		} else {
+			f.MaxTempo = math.Max(f.MaxTempo-15, 60)
+		}
+		if f.MinTempo > f.MaxTempo-10 {
+			f.MinTempo = 0
+		}
+	}
+
+	// Popularity
+	if has("more popular", "mainstream", "bigger hits", "well known", "well-known") {
+		f.MinPopularity, f.MaxPopularity = 60, 100
+	}
+	if has("less popular", "obscure", "underground", "deeper cuts", "deep cuts", "hidden gems", "lesser known") {
+		f.MinPopularity, f.MaxPopularity = 0, 50
+	}
+
+	// Era
+	year := time.Now().Year()
+	if m := decadePattern.FindStringSubmatch(q); m != nil {
+		start, _ := strconv.Atoi(m[1])
+		switch {
+		case start >= 1900:
+		case start <= 20:
+			start += 2000
+		default:
+			start += 1900
+		}
+		f.YearStart, f.YearEnd = start, start+9
+	} else if has("older", "oldies", "classic", "throwback", "retro") {
+		if f.YearStart > 0 {
+			f.YearStart, f.YearEnd = f.YearStart-10, f.YearStart-1
+		} else {
+			f.YearStart, f.YearEnd = 0, year-15
+		}
+	} else if has("newer", "recent", "latest", "modern", "fresh") {
+		if f.YearEnd > 0 && f.YearEnd < year {
+			f.YearStart, f.YearEnd = f.YearEnd+1, min(f.YearEnd+10, year)
+		} else {
+			f.YearStart, f.YearEnd = year-5, 0
+		}
+	}
+	if has("any era", "any year", "all eras") {
+		f.YearStart, f.YearEnd = 0, 0
+	}
+
+	// Genres
+	for _, m := range excludePattern.FindAllStringSubmatch(q, -1) {
+		for _, term := range genreTerms(m[1]) {
+			f.Genres = slices.DeleteFunc(f.Genres, func(g string) bool { return g == term })
+			if !slices.Contains(f.ExcludeGenres, term) {
+				f.ExcludeGenres = append(f.ExcludeGenres, term)
+			}
+		}
+	}
+	if m := onlyPattern.FindStringSubmatch(q); m != nil {
+		if terms := knownGenres(m[1]); len(terms) > 0 {
+			f.Genres = terms
+		}
+	}
+	for _, m := range includePattern.FindAllStringSubmatch(q, -1) {
+		for _, genre := range knownGenres(m[1]) {
+			if !slices.Contains(f.Genres, genre) {
+				f.Genres = append(f.Genres, genre)
+			}
+			f.ExcludeGenres = slices.DeleteFunc(f.ExcludeGenres, func(g string) bool { return g == genre })
+		}
+	}
+	if len(f.Genres) > 3 {
+		f.Genres = f.Genres[len(f.Genres)-3:]
+	}
+
+	f.clamp()
+	return f, !Equal(f, current)
+}
+
+// genreTerms returns the genres named at the start of text, including
+// excludable words that aren't recommendation genres
+func genreTerms(text string) []string {
+	if terms := knownGenres(text); len(terms) > 0 {
+		return terms
+	}
+	words := strings.Fields(text)
+	if len(words) > 0 && slices.Contains(excludableGenres, words[0]) {
+		return []string{words[0]}
+	}
+	return nil
+}
+
+// knownGenres finds recommendation genres mentioned at the start of text
+func knownGenres(text string) []string {
+	words := strings.Fields(text)
+	if len(words) > 2 {
+		words = words[:2]
+	}
+	phrase := strings.Join(words, " ")
+
+	var genres []string
+	for keyword, genre := range genreKeywords {
+		if (phrase == keyword || strings.HasPrefix(phrase, keyword+" ")) && !slices.Contains(genres, genre) {
+			genres = append(genres, genre)
+		}
+	}
+	slices.Sort(genres)
+	return genres
+}
+
+// clamp keeps attribute ranges valid
+func (f *Filters) clamp() {
+	unit := func(v float64) float64 { return math.Max(0, math.Min(1, v)) }
+	f.MinDanceability, f.MaxDanceability = unit(f.MinDanceability), unit(f.MaxDanceability)
+	f.MinEnergy, f.MaxEnergy = unit(f.MinEnergy), unit(f.MaxEnergy)
+	f.MinValence, f.MaxValence = unit(f.MinValence), unit(f.MaxValence)
+	f.MinPopularity = max(0, min(100, f.MinPopularity))
+	f.MaxPopularity = max(0, min(100, f.MaxPopularity))
+}
+
+// Equal reports whether two sets of filters are the same
+func Equal(a, b Filters) bool {
+	return slices.Equal(a.Genres, b.Genres) && slices.Equal(a.ExcludeGenres, b.ExcludeGenres) &&
+		a.MinDanceability == b.MinDanceability && a.MaxDanceability == b.MaxDanceability &&
+		a.MinEnergy == b.MinEnergy && a.MaxEnergy == b.MaxEnergy &&
+		a.MinValence == b.MinValence && a.MaxValence == b.MaxValence &&
+		a.MinTempo == b.MinTempo && a.MaxTempo == b.MaxTempo &&
+		a.MinPopularity == b.MinPopularity && a.MaxPopularity == b.MaxPopularity &&
+		a.YearStart == b.YearStart && a.YearEnd == b.YearEnd
+}
+
+// Changes describes what differs between two sets of filters, one line per
+// attribute, e.g. "Energy: 0.00–1.00 → 0.20–1.00"
+func Changes(before, after Filters) []string {
+	var lines []string
+	rng := func(name string, a1, a2, b1, b2 float64, format string) {
+		if a1 != b1 || a2 != b2 {
+			lines = append(lines, fmt.Sprintf("%s: "+format+"–"+format+" → "+format+"–"+format, name, a1, a2, b1, b2))
+		}
+	}
+	rng("Energy", before.MinEnergy, before.MaxEnergy, after.MinEnergy, after.MaxEnergy, "%.2f")
+	rng("Mood (valence)", before.MinValence, before.MaxValence, after.MinValence, after.MaxValence, "%.2f")
+	rng("Danceability", before.MinDanceability, before.MaxDanceability, after.MinDanceability, after.MaxDanceability, "%.2f")
+	rng("Tempo", before.MinTempo, before.MaxTempo, after.MinTempo, after.MaxTempo, "%.0f")
+	rng("Popularity", float64(before.MinPopularity), float64(before.MaxPopularity),
+		float64(after.MinPopularity), float64(after.MaxPopularity), "%.0f")
+
+	if before.YearStart != after.YearStart || before.YearEnd != after.YearEnd {
+		lines = append(lines, fmt.Sprintf("Years: %s → %s",
+			yearRange(before.YearStart, before.YearEnd), yearRange(after.YearStart, after.YearEnd)))
+	}
+	if d := listDiff(before.Genres, after.Genres); d != "" {
+		lines = append(lines, "Genres: "+d)
+	}
+	if d := listDiff(before.ExcludeGenres, after.ExcludeGenres); d != "" {
+		lines = append(lines, "Excluded: "+d)
+	}
+	return lines
+}
+
+func yearRange(start, end int) string {
+	switch {
+	case start == 0 && end == 0:
+		return "any"
+	case start == 0:
+		return fmt.Sprintf("up to %d", end)
+	case end == 0:
+		return fmt.Sprintf("%d onwards", start)
+	}
+	return fmt.Sprintf("%d–%d", start, end)
+}
+
+// listDiff renders added and removed items as "+house −rap"
+func listDiff(before, after []string) string {
+	var parts []string
+	for _, item := range after {
+		if !slices.Contains(before, item) {
+			parts = append(parts, "+"+item)
+		}
+	}
+	for _, item := range before {
+		if !slices.Contains(after, item) {
+			parts = append(parts, "−"+item)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRefineSimple(t *testing.T) {
+	base := Filters{MaxEnergy: 1, MaxValence: 1, MaxDanceability: 1, MaxPopularity: 100}
+	year := time.Now().Year()
+
+	tests := []struct {
+		message string
+		current Filters
+		check   func(Filters) bool
+	}{
+		{"more upbeat", base, func(f Filters) bool { return f.MinValence == step }},
+		{"calmer please", base, func(f Filters) bool { return f.MaxEnergy == 1-step }},
+		{"a bit faster", base, func(f Filters) bool { return f.MinTempo == 110 }},
+		{"deep cuts", base, func(f Filters) bool { return f.MaxPopularity == 50 }},
+		{"the 2000s", base, func(f Filters) bool { return f.YearStart == 2000 && f.YearEnd == 2009 }},
+		{"2000's", base, func(f Filters) bool { return f.YearStart == 2000 && f.YearEnd == 2009 }},
+		{"1990s", base, func(f Filters) bool { return f.YearStart == 1990 && f.YearEnd == 1999 }},
+		{"90s", base, func(f Filters) bool { return f.YearStart == 1990 && f.YearEnd == 1999 }},
+		{"2010s", base, func(f Filters) bool { return f.YearStart == 2010 && f.YearEnd == 2019 }},
+		{"10s", base, func(f Filters) bool { return f.YearStart == 2010 && f.YearEnd == 2019 }},
+		{"newer", base, func(f Filters) bool { return f.YearStart == year-5 && f.YearEnd == 0 }},
+		{"with some jazz", base, func(f Filters) bool { return slices.Equal(f.Genres, []string{"jazz"}) }},
+		{"add a bit of soul", base, func(f Filters) bool { return slices.Equal(f.Genres, []string{"soul"}) }},
+		{"no rap", base, func(f Filters) bool { return slices.Equal(f.ExcludeGenres, []string{"rap"}) }},
+		{"without any metal", Filters{Genres: []string{"metal", "rock"}, MaxEnergy: 1},
+			func(f Filters) bool {
+				return slices.Equal(f.Genres, []string{"rock"}) && slices.Equal(f.ExcludeGenres, []string{"metal"})
+			}},
+		{"only house", Filters{Genres: []string{"techno"}, MaxEnergy: 1},
+			func(f Filters) bool { return slices.Equal(f.Genres, []string{"house"}) }},
+		{"well-known stuff", base, func(f Filters) bool { return f.MinPopularity == 60 }},
+	}
+	for _, tt := range tests {
+		f, ok := RefineSimple(tt.current, tt.message)
+		if !ok || !tt.check(f) {
+			t.Errorf("RefineSimple(%q) = %+v, %v", tt.message, f, ok)
+		}
+	}
+}
+
+func TestRefineSimpleMatchesWholeWords(t *testing.T) {
+	base := Filters{MaxEnergy: 1, MaxValence: 1}
+	for _, message := range []string{"pumpkin spice", "unhappy", "hello there"} {
+		if f, ok := RefineSimple(base, message); ok {
+			t.Errorf("RefineSimple(%q) changed the filters: %+v", message, f)
+		}
+	}
+}
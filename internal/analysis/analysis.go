@@ -0,0 +1,320 @@
+// Package analysis builds audio-feature profiles of playlists, albums and
+// search results.
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/stats"
+	"github.com/zmb3/spotify/v2"
+)
+
+// minOutlierTracks is the smallest set of tracks worth looking for outliers in
+const minOutlierTracks = 8
+
+// outlierThreshold is how many standard deviations from the mean make a track an outlier
+const outlierThreshold = 2.0
+
+// Track is a track together with its audio features
+type Track struct {
+	ID       spotify.ID             `json:"id"`
+	Name     string                 `json:"name"`
+	Artist   string                 `json:"artist"`
+	Features *spotify.AudioFeatures `json:"-"`
+}
+
+// Histogram is the distribution of one audio feature
+type Histogram struct {
+	Feature string        `json:"feature"`
+	Buckets []stats.Count `json:"buckets"`
+}
+
+// Quadrant is one cell of the valence × energy mood grid
+type Quadrant struct {
+	Name   string  `json:"name"`
+	Tracks int     `json:"tracks"`
+	Share  float64 `json:"share"`
+}
+
+// Outlier is a track whose features stand out from the rest of the set
+type Outlier struct {
+	Track   string  `json:"track"`
+	Artist  string  `json:"artist"`
+	Feature string  `json:"feature"`
+	Value   float64 `json:"value"`
+	ZScore  float64 `json:"z_score"`
+}
+
+// Report is the audio profile of a set of tracks
+type Report struct {
+	Source     string                `json:"source"`
+	Tracks     int                   `json:"tracks"`
+	Analyzed   int                   `json:"analyzed"`
+	Averages   *stats.FeatureProfile `json:"averages,omitempty"`
+	Histograms []Histogram           `json:"histograms"`
+	Keys       []stats.Count         `json:"keys"`
+	MajorShare float64               `json:"major_share"`
+	Quadrants  []Quadrant            `json:"quadrants"`
+	Outliers   []Outlier             `json:"outliers"`
+	Vibe       string                `json:"vibe"`
+}
+
+// feature describes how to read, bucket and describe one audio feature
+type feature struct {
+	Name   string
+	Value  func(*spotify.AudioFeatures) float64
+	Bucket func(float64) (int, string)
+}
+
+var features = []feature{
+	{"Tempo", func(f *spotify.AudioFeatures) float64 { return float64(f.Tempo) }, tempoBucket},
+	{"Energy", func(f *spotify.AudioFeatures) float64 { return float64(f.Energy) }, unitBucket},
+	{"Valence", func(f *spotify.AudioFeatures) float64 { return float64(f.Valence) }, unitBucket},
+	{"Danceability", func(f *spotify.AudioFeatures) float64 { return float64(f.Danceability) }, unitBucket},
+	{"Acousticness", func(f *spotify.AudioFeatures) float64 { return float64(f.Acousticness) }, unitBucket},
+	{"Loudness", func(f *spotify.AudioFeatures) float64 { return float64(f.Loudness) }, loudnessBucket},
+}
+
+// Analyze profiles the given tracks. Tracks without audio features count
+// towards Tracks but are otherwise ignored. At most maxOutliers outliers are
+// reported, the most extreme first.
+func Analyze(source string, tracks []Track, maxOutliers int) *Report {
+	report := &Report{Source: source, Tracks: len(tracks)}
+
+	var analyzed []Track
+	byID := make(map[spotify.ID]*spotify.AudioFeatures, len(tracks))
+	for _, t := range tracks {
+		if t.Features != nil {
+			analyzed = append(analyzed, t)
+			byID[t.ID] = t.Features
+		}
+	}
+	report.Analyzed = len(analyzed)
+	if len(analyzed) == 0 {
+		return report
+	}
+
+	report.Averages = stats.AverageFeatures(byID)
+	for _, f := range features {
+		report.Histograms = append(report.Histograms, histogram(f, analyzed))
+	}
+	report.Keys, report.MajorShare = keyDistribution(analyzed)
+	report.Quadrants = quadrants(analyzed)
+	report.Outliers = outliers(analyzed, maxOutliers)
+	report.Vibe = Vibe(report.Averages)
+	return report
+}
+
+// histogram counts tracks per bucket, trimming empty buckets at either end
+func histogram(f feature, tracks []Track) Histogram {
+	counts := map[int]float64{}
+	labels := map[int]string{}
+	lo, hi := math.MaxInt, math.MinInt
+	for _, t := range tracks {
+		index, label := f.Bucket(f.Value(t.Features))
+		counts[index]++
+		labels[index] = label
+		if index < lo {
+			lo = index
+		}
+		if index > hi {
+			hi = index
+		}
+	}
+
+	h := Histogram{Feature: f.Name}
+	for i := lo; i <= hi; i++ {
+		label, ok := labels[i]
+		if !ok {
+			_, label = f.Bucket(bucketStart(f, i))
+		}
+		h.Buckets = append(h.Buckets, stats.Count{Label: label, Value: counts[i]})
+	}
+	return h
+}
+
+// bucketStart returns a value that falls into bucket i, used to label empty buckets
+func bucketStart(f feature, i int) float64 {
+	switch f.Name {
+	case "Tempo":
+		return float64(i * 10)
+	case "Loudness":
+		return float64(i * 3)
+	default:
+		return float64(i) * 0.2
+	}
+}
+
+func tempoBucket(v float64) (int, string) {
+	i := int(v / 10)
+	return i, fmt.Sprintf("%d-%d BPM", i*10, i*10+9)
+}
+
+func unitBucket(v float64) (int, string) {
+	i := int(v * 5)
+	if i > 4 {
+		i = 4
+	}
+	return i, fmt.Sprintf("%.1f-%.1f", float64(i)*0.2, float64(i+1)*0.2)
+}
+
+func loudnessBucket(v float64) (int, string) {
+	i := int(math.Floor(v / 3))
+	return i, fmt.Sprintf("%d to %d dB", i*3, i*3+3)
+}
+
+// keyDistribution counts tracks per key and mode, most common first, and
+// returns the share of tracks in a major key
+func keyDistribution(tracks []Track) ([]stats.Count, float64) {
+	counts := map[string]float64{}
+	major := 0
+	for _, t := range tracks {
+		if t.Features.Key < 0 {
+			continue
+		}
+		mode := int(t.Features.Mode)
+		counts[KeyName(int(t.Features.Key))+" "+ModeName(mode)]++
+		if mode == 1 {
+			major++
+		}
+	}
+
+	keys := make([]stats.Count, 0, len(counts))
+	for label, n := range counts {
+		keys = append(keys, stats.Count{Label: label, Value: n})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Value != keys[j].Value {
+			return keys[i].Value > keys[j].Value
+		}
+		return keys[i].Label < keys[j].Label
+	})
+
+	return keys, float64(major) / float64(len(tracks))
+}
+
+// Mood quadrants of the valence × energy grid
+const (
+	QuadrantHappy = "Happy & energetic"
+	QuadrantTense = "Tense & intense"
+	QuadrantSad   = "Sad & mellow"
+	QuadrantCalm  = "Calm & content"
+)
+
+// MoodQuadrant places a track on the valence × energy grid
+func MoodQuadrant(f *spotify.AudioFeatures) string {
+	switch {
+	case f.Valence >= 0.5 && f.Energy >= 0.5:
+		return QuadrantHappy
+	case f.Energy >= 0.5:
+		return QuadrantTense
+	case f.Valence < 0.5:
+		return QuadrantSad
+	default:
+		return QuadrantCalm
+	}
+}
+
+func quadrants(tracks []Track) []Quadrant {
+	counts := map[string]int{}
+	for _, t := range tracks {
+		counts[MoodQuadrant(t.Features)]++
+	}
+
+	result := make([]Quadrant, 0, 4)
+	for _, name := range []string{QuadrantHappy, QuadrantTense, QuadrantSad, QuadrantCalm} {
+		result = append(result, Quadrant{
+			Name:   name,
+			Tracks: counts[name],
+			Share:  float64(counts[name]) / float64(len(tracks)),
+		})
+	}
+	return result
+}
+
+// outliers finds tracks far from the mean of any feature, reporting each
+// track once for its most extreme feature
+func outliers(tracks []Track, max int) []Outlier {
+	if len(tracks) < minOutlierTracks || max <= 0 {
+		return nil
+	}
+
+	type moments struct{ mean, std float64 }
+	stat := make([]moments, len(features))
+	for i, f := range features {
+		var sum, sumSq float64
+		for _, t := range tracks {
+			v := f.Value(t.Features)
+			sum += v
+			sumSq += v * v
+		}
+		n := float64(len(tracks))
+		mean := sum / n
+		stat[i] = moments{mean, math.Sqrt(math.Max(sumSq/n-mean*mean, 0))}
+	}
+
+	var result []Outlier
+	for _, t := range tracks {
+		var worst *Outlier
+		for i, f := range features {
+			if stat[i].std == 0 {
+				continue
+			}
+			v := f.Value(t.Features)
+			z := (v - stat[i].mean) / stat[i].std
+			if math.Abs(z) >= outlierThreshold && (worst == nil || math.Abs(z) > math.Abs(worst.ZScore)) {
+				worst = &Outlier{Track: t.Name, Artist: t.Artist, Feature: f.Name, Value: v, ZScore: z}
+			}
+		}
+		if worst != nil {
+			result = append(result, *worst)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return math.Abs(result[i].ZScore) > math.Abs(result[j].ZScore)
+	})
+	if len(result) > max {
+		result = result[:max]
+	}
+	return result
+}
+
+// Vibe describes a feature profile as a short phrase that `moodify search`
+// understands, e.g. "happy high-energy danceable songs around 124 bpm"
+func Vibe(p *stats.FeatureProfile) string {
+	if p == nil {
+		return ""
+	}
+
+	var words []string
+	switch {
+	case p.Valence >= 0.65:
+		words = append(words, "happy")
+	case p.Valence <= 0.35:
+		words = append(words, "melancholy")
+	}
+	switch {
+	case p.Energy >= 0.7:
+		words = append(words, "high-energy")
+	case p.Energy <= 0.4:
+		words = append(words, "chill")
+	}
+	if p.Danceability >= 0.7 {
+		words = append(words, "danceable")
+	}
+	if p.Acousticness >= 0.6 {
+		words = append(words, "acoustic")
+	}
+	if p.Instrumentalness >= 0.5 {
+		words = append(words, "instrumental")
+	}
+	if len(words) == 0 {
+		words = append(words, "easygoing")
+	}
+
+	return fmt.Sprintf("%s songs around %d bpm", strings.Join(words, " "), int(math.Round(p.Tempo)))
+}
@@ -0,0 +1,77 @@
+package analysis
+
+import "fmt"
+
+// keyNames maps Spotify's pitch-class key integers to note names
+var keyNames = []string{"C", "C#/Db", "D", "D#/Eb", "E", "F", "F#/Gb", "G", "G#/Ab", "A", "A#/Bb", "B"}
+
+// KeyName converts a Spotify key integer (0 = C, 1 = C#, ...) into a note name
+func KeyName(key int) string {
+	if key >= 0 && key < len(keyNames) {
+		return keyNames[key]
+	}
+	return "Unknown"
+}
+
+// ModeName converts a Spotify mode (1 = major, 0 = minor) into a name
+func ModeName(mode int) string {
+	if mode == 1 {
+		return "major"
+	}
+	return "minor"
+}
+
+// Camelot returns a key's position on the Camelot wheel used by DJs: a number
+// from 1 to 12 and whether it's in the minor (A) ring. Keys a fifth apart are
+// neighbours, and relative major/minor keys share a number. ok is false when
+// Spotify couldn't detect the key.
+func Camelot(key, mode int) (number int, minor bool, ok bool) {
+	if key < 0 || key > 11 {
+		return 0, false, false
+	}
+
+	offset := 8 // C major is 8B
+	if mode != 1 {
+		offset = 5 // C minor is 5A
+	}
+	number = (key*7 + offset) % 12
+	if number == 0 {
+		number = 12
+	}
+	return number, mode != 1, true
+}
+
+// CamelotCode returns a key's Camelot notation, e.g. "8A" for A minor
+func CamelotCode(key, mode int) string {
+	number, minor, ok := Camelot(key, mode)
+	if !ok {
+		return "?"
+	}
+	if minor {
+		return fmt.Sprintf("%dA", number)
+	}
+	return fmt.Sprintf("%dB", number)
+}
+
+// CamelotDistance counts the steps between two keys on the Camelot wheel:
+// 0 for the same key, 1 for a neighbouring number or the relative
+// major/minor, and more for harder mixes. It returns -1 if either key is unknown.
+func CamelotDistance(keyA, modeA, keyB, modeB int) int {
+	numberA, minorA, okA := Camelot(keyA, modeA)
+	numberB, minorB, okB := Camelot(keyB, modeB)
+	if !okA || !okB {
+		return -1
+	}
+
+	steps := numberA - numberB
+	if steps < 0 {
+		steps = -steps
+	}
+	if steps > 6 {
+		steps = 12 - steps
+	}
+	if minorA != minorB {
+		steps++
+	}
+	return steps
+}
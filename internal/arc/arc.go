@@ -0,0 +1,221 @@
+// Package arc shapes playlists along an energy curve, such as a warm-up,
+// peak and cool-down, over a target duration.
+package arc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxEnergyGap is the furthest a track's energy may be from its segment's
+// target; segments that run short carry the gap over to the next one
+const maxEnergyGap = 0.3
+
+// Level is a named segment type that arcs are built from
+type Level struct {
+	Name string
+	// Energy is the target energy (0-1) of the segment
+	Energy float64
+	// TempoFactor scales the base tempo for the segment
+	TempoFactor float64
+}
+
+// Levels are the segment names an arc can be built from
+var Levels = []Level{
+	{"chill", 0.25, 0.85},
+	{"cooldown", 0.35, 0.88},
+	{"warmup", 0.45, 0.92},
+	{"steady", 0.6, 1.0},
+	{"build", 0.7, 1.0},
+	{"high", 0.8, 1.03},
+	{"peak", 0.92, 1.06},
+}
+
+// Presets are named shorthands for common arcs
+var Presets = map[string]string{
+	"workout":   "warmup-build-peak-peak-cooldown",
+	"party":     "warmup-build-peak-high-peak",
+	"wind-down": "steady-cooldown-chill",
+	"wave":      "steady-peak-cooldown-peak-cooldown",
+}
+
+// Segment is one planned part of an arc
+type Segment struct {
+	Name     string        `json:"name"`
+	Energy   float64       `json:"energy"`
+	Tempo    float64       `json:"tempo"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Plan is an arc laid out over a target duration
+type Plan struct {
+	Shape    string        `json:"shape"`
+	Duration time.Duration `json:"duration"`
+	Segments []Segment     `json:"segments"`
+}
+
+// Candidate is a track that can be placed in a plan
+type Candidate struct {
+	// Index is the candidate's position in the caller's list
+	Index    int
+	Duration time.Duration
+	Energy   float64
+	Tempo    float64
+}
+
+// Placement is a candidate chosen for a segment
+type Placement struct {
+	Candidate
+	Segment int
+}
+
+// Parse turns a preset name or a dash-separated list of levels
+// ("warmup-peak-cooldown") into level definitions
+func Parse(shape string) ([]Level, error) {
+	shape = strings.ToLower(strings.TrimSpace(shape))
+	if preset, ok := Presets[shape]; ok {
+		shape = preset
+	}
+
+	var levels []Level
+	for _, name := range strings.Split(shape, "-") {
+		level, ok := findLevel(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown arc segment %q (use %s, or a preset: %s)",
+				name, strings.Join(LevelNames(), ", "), strings.Join(PresetNames(), ", "))
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// NewPlan spreads the levels of shape evenly over duration. Segment tempos
+// are relative to baseTempo.
+func NewPlan(shape string, duration time.Duration, baseTempo float64) (*Plan, error) {
+	levels, err := Parse(shape)
+	if err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("an arc needs a positive duration")
+	}
+
+	plan := &Plan{Shape: shape, Duration: duration}
+	each := duration / time.Duration(len(levels))
+	for _, level := range levels {
+		plan.Segments = append(plan.Segments, Segment{
+			Name:     level.Name,
+			Energy:   level.Energy,
+			Tempo:    math.Round(baseTempo * level.TempoFactor),
+			Duration: each,
+		})
+	}
+	return plan, nil
+}
+
+// Assemble fills each segment with the candidates closest to its energy and
+// tempo targets until the segment's duration is reached. Any shortfall or
+// overrun carries over to the next segment so the total lands near the plan's
+// duration. Within a segment, tracks are ordered to glide towards the next
+// segment's energy.
+func (p *Plan) Assemble(candidates []Candidate) []Placement {
+	used := make(map[int]bool, len(candidates))
+	var placements []Placement
+	var carry time.Duration
+
+	for s, segment := range p.Segments {
+		target := segment.Duration + carry
+		pool := make([]Candidate, 0, len(candidates))
+		for _, c := range candidates {
+			if !used[c.Index] && c.Duration > 0 && math.Abs(c.Energy-segment.Energy) <= maxEnergyGap {
+				pool = append(pool, c)
+			}
+		}
+		sort.SliceStable(pool, func(i, j int) bool {
+			return segment.fit(pool[i]) < segment.fit(pool[j])
+		})
+
+		var filled time.Duration
+		var picked []Placement
+		slack := 30 * time.Second
+		for _, c := range pool {
+			if filled >= target-slack {
+				break
+			}
+			if filled+c.Duration > target+slack {
+				continue
+			}
+			used[c.Index] = true
+			filled += c.Duration
+			picked = append(picked, Placement{Candidate: c, Segment: s})
+		}
+
+		rising := s+1 < len(p.Segments) && p.Segments[s+1].Energy > segment.Energy
+		sort.SliceStable(picked, func(i, j int) bool {
+			if rising {
+				return picked[i].Energy < picked[j].Energy
+			}
+			return picked[i].Energy > picked[j].Energy
+		})
+
+		placements = append(placements, picked...)
+		carry = target - filled
+	}
+
+	return placements
+}
+
+// EnergyAt returns the planned energy at an offset into the plan
+func (p *Plan) EnergyAt(offset time.Duration) float64 {
+	var elapsed time.Duration
+	for _, segment := range p.Segments {
+		elapsed += segment.Duration
+		if offset < elapsed {
+			return segment.Energy
+		}
+	}
+	if len(p.Segments) == 0 {
+		return 0
+	}
+	return p.Segments[len(p.Segments)-1].Energy
+}
+
+// fit scores how well a candidate suits the segment; lower is better
+func (s Segment) fit(c Candidate) float64 {
+	score := math.Abs(c.Energy - s.Energy)
+	if s.Tempo > 0 && c.Tempo > 0 {
+		score += math.Abs(c.Tempo-s.Tempo) / s.Tempo
+	}
+	return score
+}
+
+// LevelNames lists the segment names in order of energy
+func LevelNames() []string {
+	names := make([]string, 0, len(Levels))
+	for _, level := range Levels {
+		names = append(names, level.Name)
+	}
+	return names
+}
+
+// PresetNames lists the preset arc names alphabetically
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func findLevel(name string) (Level, bool) {
+	for _, level := range Levels {
+		if level.Name == name {
+			return level, true
+		}
+	}
+	return Level{}, false
+}
@@ -13,10 +13,12 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
-	"os/user"
-	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/lorrehuggan/moodify/internal/config"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
@@ -33,7 +35,7 @@ const (
 
 	// File names
 	TokenFileName = "token.json"
-	ConfigDirName = "moodify"
+	ConfigDirName = config.DirName
 )
 
 var (
@@ -46,6 +48,18 @@ var (
 		"8000", // HTTP alternative
 		"9000", // High port
 	}
+
+	// Scopes requested at login, covering every command so one login is enough
+	DefaultScopes = []string{
+		spotifyauth.ScopeUserTopRead,
+		spotifyauth.ScopePlaylistModifyPrivate,
+		spotifyauth.ScopePlaylistModifyPublic,
+		spotifyauth.ScopePlaylistReadPrivate,
+		spotifyauth.ScopeUserReadPrivate,
+		spotifyauth.ScopeUserReadCurrentlyPlaying,
+		spotifyauth.ScopeUserReadPlaybackState,
+		spotifyauth.ScopeUserModifyPlaybackState,
+	}
 )
 
 // Config holds authentication configuration
@@ -62,6 +76,12 @@ type TokenStore struct {
 	RefreshToken string    `json:"refresh_token"`
 	TokenType    string    `json:"token_type"`
 	Expiry       time.Time `json:"expiry"`
+	Scope        string    `json:"scope,omitempty"` // space-separated scopes granted at login
+
+	// Set when Spotify last rejected the refresh token; cleared by the next
+	// successful login or refresh, which rewrites the file
+	RefreshError    string     `json:"refresh_error,omitempty"`
+	RefreshFailedAt *time.Time `json:"refresh_failed_at,omitempty"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -70,11 +90,7 @@ func DefaultConfig() *Config {
 		ClientID:    DefaultClientID,
 		RedirectURI: DefaultRedirectURI,
 		Port:        DefaultPort,
-		Scopes: []string{
-			spotifyauth.ScopeUserTopRead,
-			spotifyauth.ScopePlaylistModifyPrivate,
-			spotifyauth.ScopeUserReadPrivate,
-		},
+		Scopes:      DefaultScopes,
 	}
 }
 
@@ -87,28 +103,12 @@ func ConfigWithClientID(clientID string) *Config {
 
 // getConfigDir returns the user's configuration directory
 func getConfigDir() (string, error) {
-	usr, err := user.Current()
-	if err != nil {
-		return "", fmt.Errorf("failed to get current user: %w", err)
-	}
-
-	configDir := filepath.Join(usr.HomeDir, ".config", ConfigDirName)
-	return configDir, nil
+	return config.Dir()
 }
 
 // getTokenPath returns the path to the token file
 func getTokenPath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-
-	// Ensure config directory exists
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	return filepath.Join(configDir, TokenFileName), nil
+	return config.Path(TokenFileName)
 }
 
 // generateCodeVerifier generates a random code verifier for PKCE
@@ -133,16 +133,20 @@ func generateState() string {
 
 // saveToken saves a token to disk with secure permissions
 func saveToken(token *oauth2.Token) error {
-	tokenPath, err := getTokenPath()
-	if err != nil {
-		return err
-	}
-
-	tokenStore := &TokenStore{
+	return writeTokenStore(&TokenStore{
 		AccessToken:  token.AccessToken,
 		RefreshToken: token.RefreshToken,
 		TokenType:    token.TokenType,
 		Expiry:       token.Expiry,
+		Scope:        tokenScope(token),
+	})
+}
+
+// writeTokenStore writes the token file, readable and writable only by its owner
+func writeTokenStore(tokenStore *TokenStore) error {
+	tokenPath, err := getTokenPath()
+	if err != nil {
+		return err
 	}
 
 	data, err := json.MarshalIndent(tokenStore, "", "  ")
@@ -160,6 +164,22 @@ func saveToken(token *oauth2.Token) error {
 
 // loadToken loads a token from disk
 func loadToken() (*oauth2.Token, error) {
+	tokenStore, err := readTokenStore()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tokenStore.AccessToken,
+		RefreshToken: tokenStore.RefreshToken,
+		TokenType:    tokenStore.TokenType,
+		Expiry:       tokenStore.Expiry,
+	}
+	return withScope(token, tokenStore.Scope), nil
+}
+
+// readTokenStore reads the token file
+func readTokenStore() (*TokenStore, error) {
 	tokenPath, err := getTokenPath()
 	if err != nil {
 		return nil, err
@@ -168,7 +188,7 @@ func loadToken() (*oauth2.Token, error) {
 	data, err := os.ReadFile(tokenPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("no token found, please run login first")
+			return nil, errs.Wrap(errs.ErrNotAuthenticated, fmt.Errorf("no token found, please run login first"))
 		}
 		return nil, fmt.Errorf("failed to read token file: %w", err)
 	}
@@ -177,13 +197,21 @@ func loadToken() (*oauth2.Token, error) {
 	if err := json.Unmarshal(data, &tokenStore); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
 	}
+	return &tokenStore, nil
+}
 
-	return &oauth2.Token{
-		AccessToken:  tokenStore.AccessToken,
-		RefreshToken: tokenStore.RefreshToken,
-		TokenType:    tokenStore.TokenType,
-		Expiry:       tokenStore.Expiry,
-	}, nil
+// tokenScope returns the space-separated scopes granted with a token
+func tokenScope(token *oauth2.Token) string {
+	scope, _ := token.Extra("scope").(string)
+	return scope
+}
+
+// withScope records the scopes granted with a token
+func withScope(token *oauth2.Token, scope string) *oauth2.Token {
+	if scope == "" {
+		return token
+	}
+	return token.WithExtra(map[string]interface{}{"scope": scope})
 }
 
 // deleteToken removes the stored token file
@@ -389,7 +417,7 @@ func exchangeCodeForToken(_ context.Context, config *Config, code, codeVerifier
 		Expiry:       time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
 	}
 
-	return token, nil
+	return withScope(token, tokenResp.Scope), nil
 }
 
 // Logout removes stored credentials
@@ -409,11 +437,10 @@ func GetAuthenticatedClient(ctx context.Context, config *Config) (*spotify.Clien
 	}
 
 	// Check if token needs refresh
-	if token.Expiry.Before(time.Now().Add(5 * time.Minute)) {
-		log.Println("Token expired or expiring soon, refreshing...")
-		refreshedToken, err := refreshToken(ctx, config, token.RefreshToken)
+	if token.Expiry.Before(time.Now().Add(refreshMargin)) {
+		refreshedToken, err := refreshStored(ctx, config, token)
 		if err != nil {
-			return nil, fmt.Errorf("failed to refresh token: %w", err)
+			return nil, err
 		}
 
 		// Save refreshed token
@@ -435,13 +462,19 @@ func GetAuthenticatedClient(ctx context.Context, config *Config) (*spotify.Clien
 		Scopes:      config.Scopes,
 	}
 
-	// Create HTTP client with token
-	httpClient := oauthConfig.Client(ctx, token)
-
-	// Create Spotify client
-	client := spotify.New(httpClient)
+	return spotify.New(newHTTPClient(oauthConfig.TokenSource(ctx, token))), nil
+}
 
-	return client, nil
+// newHTTPClient returns an HTTP client for the Spotify API that answers
+// slow-changing lookups from the cache, authenticates with tokens, and retries
+// rate-limited and transiently failing requests
+func newHTTPClient(tokens oauth2.TokenSource) *http.Client {
+	return &http.Client{
+		Transport: spotifyx.NewCachingTransport(&oauth2.Transport{
+			Source: tokens,
+			Base:   spotifyx.NewRetryingTransport(nil),
+		}),
+	}
 }
 
 // refreshToken refreshes an expired access token
@@ -459,7 +492,23 @@ func refreshToken(_ context.Context, config *Config, refreshToken string) (*oaut
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("refresh request failed with status %d", resp.StatusCode)
+		err := fmt.Errorf("refresh request failed with status %d", resp.StatusCode)
+		var errResp struct {
+			Code        string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Description != "" {
+			err = fmt.Errorf("%w: %s", err, errResp.Description)
+		}
+		switch errResp.Code {
+		case "invalid_grant":
+			// The refresh token was revoked or has expired
+			return nil, errs.Wrap(errs.ErrNotAuthenticated, err)
+		case "invalid_client":
+			// Our configuration is wrong, and the login may still be good
+			return nil, fmt.Errorf("%w (check SPOTIFY_CLIENT_ID)", err)
+		}
+		return nil, err
 	}
 
 	var tokenResp struct {
@@ -486,7 +535,7 @@ func refreshToken(_ context.Context, config *Config, refreshToken string) (*oaut
 		Expiry:       time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
 	}
 
-	return token, nil
+	return withScope(token, tokenResp.Scope), nil
 }
 
 // GetClientIDFromEnv returns the client ID from environment or default
@@ -517,7 +566,7 @@ func SmartLogin(ctx context.Context) error {
 	for i, port := range CommonPorts {
 		fmt.Printf("   Trying port %s... ", port)
 
-		if !isPortAvailable(port) {
+		if !PortAvailable(port) {
 			fmt.Println("❌ in use")
 			continue
 		}
@@ -528,11 +577,7 @@ func SmartLogin(ctx context.Context) error {
 			ClientID:    getSmartClientID(),
 			RedirectURI: fmt.Sprintf("http://127.0.0.1:%s/callback", port),
 			Port:        port,
-			Scopes: []string{
-				"user-top-read",
-				"playlist-modify-private",
-				"user-read-private",
-			},
+			Scopes:      DefaultScopes,
 		}
 
 		fmt.Printf("🔗 Using redirect URI: %s\n", config.RedirectURI)
@@ -569,8 +614,8 @@ func getSmartClientID() string {
 	return DefaultClientID
 }
 
-// isPortAvailable checks if a port is available for listening
-func isPortAvailable(port string) bool {
+// PortAvailable checks if a port is available for listening
+func PortAvailable(port string) bool {
 	ln, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		return false
@@ -609,15 +654,25 @@ func showFallbackHelp(lastError error) error {
 	return fmt.Errorf("authentication setup required")
 }
 
-// QuickCheck verifies if user is already authenticated
+// QuickCheck verifies if user is already authenticated, counting logins whose
+// access token has expired but can be refreshed
 func QuickCheck() bool {
+	state := CheckToken().State
+	return state == TokenValid || state == TokenRefreshable
+}
+
+// GrantedScopes returns the scopes the saved token was granted. known is false
+// for tokens saved before moodify started recording scopes.
+func GrantedScopes() (scopes []string, known bool, err error) {
 	token, err := loadToken()
 	if err != nil {
-		return false
+		return nil, false, err
 	}
-
-	// Check if token is valid (not expired)
-	return token.Expiry.After(time.Now().Add(1 * time.Minute))
+	scope := tokenScope(token)
+	if scope == "" {
+		return nil, false, nil
+	}
+	return strings.Fields(scope), true, nil
 }
 
 // LoadTokenForStatus returns token info for status display (exported version)
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+)
+
+// refreshMargin is how long before expiry a token is refreshed
+const refreshMargin = 5 * time.Minute
+
+// TokenSource hands out the stored token for long-running processes such as
+// `moodify serve`, refreshing it shortly before it expires and saving the
+// refreshed token so other moodify commands pick it up. It is safe for
+// concurrent use.
+type TokenSource struct {
+	config *Config
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// NewTokenSource starts from the token saved by `moodify login`
+func NewTokenSource(config *Config) (*TokenSource, error) {
+	token, err := loadToken()
+	if err != nil {
+		return nil, err
+	}
+	return &TokenSource{config: config, token: token}, nil
+}
+
+// Token returns a token that is valid for at least a few more minutes
+func (s *TokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Expiry.After(time.Now().Add(refreshMargin)) {
+		return s.token, nil
+	}
+
+	// Another moodify command may already have refreshed it; Spotify rotates
+	// refresh tokens, so prefer the saved one when it's newer
+	if stored, err := loadToken(); err == nil && stored.Expiry.After(s.token.Expiry) {
+		s.token = stored
+		if s.token.Expiry.After(time.Now().Add(refreshMargin)) {
+			return s.token, nil
+		}
+	}
+
+	refreshed, err := refreshStored(context.Background(), s.config, s.token)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveToken(refreshed); err != nil {
+		return nil, fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	s.token = refreshed
+	return s.token, nil
+}
+
+// Expiry returns when the current token expires
+func (s *TokenSource) Expiry() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token.Expiry
+}
+
+// KeepFresh checks the token every interval until ctx is done, refreshing it
+// ahead of expiry so requests never wait on a refresh. Failed refreshes are
+// passed to onError and retried on the next tick.
+func (s *TokenSource) KeepFresh(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Token(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Client returns a Spotify client authenticated by the token source
+func (s *TokenSource) Client(ctx context.Context) *spotify.Client {
+	return spotify.New(newHTTPClient(s))
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"golang.org/x/oauth2"
+)
+
+// TokenState is how usable the saved login is
+type TokenState int
+
+const (
+	// TokenMissing means nobody has logged in, or the token file can't be read
+	TokenMissing TokenState = iota
+	// TokenValid means the access token is good for a while yet
+	TokenValid
+	// TokenRefreshable means the access token has expired, or soon will, and
+	// the stored refresh token can replace it without a new login
+	TokenRefreshable
+	// TokenRefreshFailed means Spotify rejected the refresh token, or none was
+	// stored, so only a new login helps
+	TokenRefreshFailed
+)
+
+func (s TokenState) String() string {
+	switch s {
+	case TokenValid:
+		return "valid"
+	case TokenRefreshable:
+		return "refreshable"
+	case TokenRefreshFailed:
+		return "refresh_failed"
+	}
+	return "missing"
+}
+
+// TokenStatus describes the saved login, as far as can be told without
+// contacting Spotify
+type TokenStatus struct {
+	State           TokenState
+	Expiry          time.Time // when the access token expires
+	HasRefreshToken bool
+	RefreshError    string    // why refreshing failed, for TokenRefreshFailed
+	RefreshFailedAt time.Time // when refreshing last failed, if it has
+	Err             error     // why the token couldn't be read, for TokenMissing
+}
+
+// CheckToken reports the state of the saved login
+func CheckToken() TokenStatus {
+	tokenStore, err := readTokenStore()
+	if err != nil {
+		return TokenStatus{State: TokenMissing, Err: err}
+	}
+
+	status := TokenStatus{
+		Expiry:          tokenStore.Expiry,
+		HasRefreshToken: tokenStore.RefreshToken != "",
+	}
+	switch {
+	case tokenStore.RefreshFailedAt != nil:
+		status.State = TokenRefreshFailed
+		status.RefreshError = tokenStore.RefreshError
+		status.RefreshFailedAt = *tokenStore.RefreshFailedAt
+	case tokenStore.Expiry.After(time.Now().Add(refreshMargin)):
+		status.State = TokenValid
+	case status.HasRefreshToken:
+		status.State = TokenRefreshable
+	default:
+		status.State = TokenRefreshFailed
+		status.RefreshError = "no refresh token saved"
+	}
+	return status
+}
+
+// RefreshNow refreshes the saved token with Spotify whatever its expiry, and
+// saves the result. It checks that the refresh token still works.
+func RefreshNow(ctx context.Context, config *Config) (*oauth2.Token, error) {
+	token, err := loadToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed, err := refreshStored(ctx, config, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveToken(refreshed); err != nil {
+		return nil, fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	return refreshed, nil
+}
+
+// refreshStored exchanges the refresh token of the saved token for a new
+// token, keeping the granted scopes. When Spotify rejects the refresh token
+// the failure is recorded in the token file for CheckToken.
+func refreshStored(ctx context.Context, config *Config, token *oauth2.Token) (*oauth2.Token, error) {
+	if token.RefreshToken == "" {
+		return nil, errs.Wrap(errs.ErrNotAuthenticated, errors.New("login expired and no refresh token is saved"))
+	}
+
+	refreshed, err := refreshToken(ctx, config, token.RefreshToken)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotAuthenticated) {
+			markRefreshFailed(err)
+		}
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	if tokenScope(refreshed) == "" {
+		// Spotify may leave the scope out of refresh responses
+		refreshed = withScope(refreshed, tokenScope(token))
+	}
+	return refreshed, nil
+}
+
+// markRefreshFailed records a rejected refresh token in the token file. It is
+// best effort: the caller reports the failure either way.
+func markRefreshFailed(cause error) {
+	tokenStore, err := readTokenStore()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	tokenStore.RefreshError = cause.Error()
+	tokenStore.RefreshFailedAt = &now
+	_ = writeTokenStore(tokenStore)
+}
@@ -0,0 +1,102 @@
+// Package cache stores small JSON snapshots in the user's cache directory so
+// frequently repeated commands can skip the Spotify API.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DirName is the name of moodify's directory inside the user cache directory
+const DirName = "moodify"
+
+// entry is the on-disk format of a cached value
+type entry struct {
+	SavedAt time.Time       `json:"saved_at"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Dir returns the moodify cache directory, creating it if needed
+func Dir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+
+	dir := filepath.Join(base, DirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// Load reads the named entry into v if it was saved less than ttl ago. It
+// returns when the entry was saved and whether a fresh entry was found.
+func Load(name string, ttl time.Duration, v interface{}) (time.Time, bool) {
+	dir, err := Dir()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return time.Time{}, false
+	}
+
+	if time.Since(e.SavedAt) > ttl {
+		return e.SavedAt, false
+	}
+
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return time.Time{}, false
+	}
+	return e.SavedAt, true
+}
+
+// Save stores v under the given name
+func Save(name string, v interface{}) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	data, err := json.Marshal(entry{SavedAt: time.Now(), Data: raw})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	// Write to a temp file of our own first, so concurrent readers never see a
+	// partial entry and concurrent writers don't share a temp file
+	path := filepath.Join(dir, name+".json")
+	tmp, err := os.CreateTemp(dir, name+".json.*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
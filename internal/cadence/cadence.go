@@ -0,0 +1,196 @@
+// Package cadence matches track tempos to a running cadence in steps per
+// minute, including half-time and double-time tracks.
+package cadence
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/fit"
+)
+
+// Ways a track's beat can line up with the runner's steps
+const (
+	// OnBeat is one step per beat
+	OnBeat = "on the beat"
+	// HalfTime is two steps per beat, e.g. an 85 BPM track at 170 spm
+	HalfTime = "half-time"
+	// DoubleTime is one step every other beat, e.g. a 340 BPM reading at 170 spm
+	DoubleTime = "double-time"
+)
+
+// ratios maps each alignment to the number of steps per beat
+var ratios = []struct {
+	name  string
+	steps float64
+}{
+	{OnBeat, 1},
+	{HalfTime, 2},
+	{DoubleTime, 0.5},
+}
+
+// Match describes how a track's tempo fits a cadence
+type Match struct {
+	// Alignment is OnBeat, HalfTime or DoubleTime
+	Alignment string
+	// Steps is the tempo converted to steps per minute
+	Steps float64
+	// Off is how far Steps is from the cadence
+	Off float64
+}
+
+// Fit reports whether tempo (in BPM) fits cadence (in steps per minute)
+// within tolerance, trying the beat, half-time and double-time in turn
+func Fit(tempo, cadence, tolerance float64) (Match, bool) {
+	if tempo <= 0 || cadence <= 0 {
+		return Match{}, false
+	}
+
+	best := Match{Off: math.Inf(1)}
+	for _, r := range ratios {
+		steps := tempo * r.steps
+		if off := math.Abs(steps - cadence); off < best.Off {
+			best = Match{Alignment: r.name, Steps: steps, Off: off}
+		}
+	}
+	return best, best.Off <= tolerance
+}
+
+// Stage is a stretch of a run held at one cadence
+type Stage struct {
+	Cadence  float64
+	Duration time.Duration
+}
+
+// Ramp splits duration into stages stepping from start to end cadence by
+// roughly step steps per minute. Without a ramp (end of 0 or equal to start)
+// it returns a single stage.
+func Ramp(start, end float64, duration time.Duration, step float64) ([]Stage, error) {
+	if start <= 0 {
+		return nil, fmt.Errorf("cadence must be positive")
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("a run needs a positive duration")
+	}
+	if end <= 0 || end == start {
+		return []Stage{{Cadence: start, Duration: duration}}, nil
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("ramp step must be positive")
+	}
+
+	count := int(math.Round(math.Abs(end-start)/step)) + 1
+	if count < 2 {
+		count = 2
+	}
+	// Keep stages at least a few minutes long so there's room for a track or two
+	if limit := int(duration / (3 * time.Minute)); count > limit {
+		count = max(limit, 1)
+	}
+	if count == 1 {
+		return []Stage{{Cadence: start, Duration: duration}}, nil
+	}
+
+	stages := make([]Stage, count)
+	each := duration / time.Duration(count)
+	for i := range stages {
+		stages[i] = Stage{
+			Cadence:  math.Round(start + (end-start)*float64(i)/float64(count-1)),
+			Duration: each,
+		}
+	}
+	return stages, nil
+}
+
+// Candidate is a track that can be placed in a run
+type Candidate struct {
+	// Index is the candidate's position in the caller's list
+	Index    int
+	Tempo    float64
+	Duration time.Duration
+}
+
+// Placement is a candidate chosen for a stage
+type Placement struct {
+	Candidate
+	Stage int
+	Match Match
+}
+
+// Options controls how a run is assembled
+type Options struct {
+	// Tolerance is how far, in steps per minute, a track may be from the cadence
+	Tolerance float64
+	// DurationTolerance is how far each stage may run over or under
+	DurationTolerance time.Duration
+	// Method is the fit selection method
+	Method string
+}
+
+// Assemble fills each stage with the candidates closest to its cadence until
+// the stage's duration is reached. Any shortfall or overrun carries over to the
+// next stage. When ramping, tracks within a stage are ordered towards the next
+// stage's cadence.
+func Assemble(stages []Stage, candidates []Candidate, opts Options) ([]Placement, error) {
+	used := make(map[int]bool, len(candidates))
+	var placements []Placement
+	var carry time.Duration
+
+	for s, stage := range stages {
+		target := stage.Duration + carry
+		if target <= 0 {
+			carry = target
+			continue
+		}
+
+		var pool []Placement
+		for _, c := range candidates {
+			if used[c.Index] {
+				continue
+			}
+			if m, ok := Fit(c.Tempo, stage.Cadence, opts.Tolerance); ok {
+				pool = append(pool, Placement{Candidate: c, Stage: s, Match: m})
+			}
+		}
+		// Closest tempos first, so fit prefers them
+		sort.SliceStable(pool, func(i, j int) bool {
+			return pool[i].Match.Off < pool[j].Match.Off
+		})
+
+		durations := make([]time.Duration, 0, len(pool))
+		for _, p := range pool {
+			durations = append(durations, p.Duration)
+		}
+		result, err := fit.Select(durations, fit.Options{
+			Target:    target,
+			Tolerance: opts.DurationTolerance,
+			Method:    opts.Method,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		picked := make([]Placement, 0, len(result.Indexes))
+		for _, i := range result.Indexes {
+			used[pool[i].Index] = true
+			picked = append(picked, pool[i])
+		}
+
+		if s+1 < len(stages) && stages[s+1].Cadence != stage.Cadence {
+			rising := stages[s+1].Cadence > stage.Cadence
+			sort.SliceStable(picked, func(i, j int) bool {
+				if rising {
+					return picked[i].Match.Steps < picked[j].Match.Steps
+				}
+				return picked[i].Match.Steps > picked[j].Match.Steps
+			})
+		}
+
+		placements = append(placements, picked...)
+		carry = target - result.Total
+	}
+
+	return placements, nil
+}
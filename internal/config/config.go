@@ -0,0 +1,37 @@
+// Package config locates moodify's configuration directory.
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// DirName is the name of moodify's directory inside ~/.config
+const DirName = "moodify"
+
+// Dir returns the user's moodify configuration directory
+func Dir() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return filepath.Join(usr.HomeDir, ".config", DirName), nil
+}
+
+// Path returns the path to a file in the configuration directory, creating
+// the directory if it doesn't exist yet
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(dir, name), nil
+}
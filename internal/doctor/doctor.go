@@ -0,0 +1,93 @@
+// Package doctor diagnoses moodify's setup: local files and ports, the Spotify
+// login and API, and the AI provider.
+package doctor
+
+import (
+	"runtime"
+	"time"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn" // works, but something is off
+	Fail Status = "fail"
+	Skip Status = "skip" // not run because a check it depends on failed
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Group       string `json:"group"` // "local", "spotify" or "ai"
+	Name        string `json:"name"`
+	Status      Status `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"` // what to do about a warning or failure
+}
+
+// Summary counts results by status
+type Summary struct {
+	Pass int `json:"pass"`
+	Warn int `json:"warn"`
+	Fail int `json:"fail"`
+	Skip int `json:"skip"`
+}
+
+// Report is the full diagnosis, suitable for attaching to a bug report. It
+// never contains tokens or keys.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	GoVersion   string    `json:"go_version"`
+	ClientID    string    `json:"client_id"` // "shared" or "custom"
+	Results     []Result  `json:"results"`
+	Summary     Summary   `json:"summary"`
+}
+
+// NewReport collects results into a report
+func NewReport(clientID string, results []Result) *Report {
+	report := &Report{
+		GeneratedAt: time.Now(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		GoVersion:   runtime.Version(),
+		ClientID:    clientID,
+		Results:     results,
+	}
+	for _, result := range results {
+		switch result.Status {
+		case Pass:
+			report.Summary.Pass++
+		case Warn:
+			report.Summary.Warn++
+		case Fail:
+			report.Summary.Fail++
+		case Skip:
+			report.Summary.Skip++
+		}
+	}
+	return report
+}
+
+// Healthy reports whether no check failed
+func (r *Report) Healthy() bool {
+	return r.Summary.Fail == 0
+}
+
+func pass(group, name, message string) Result {
+	return Result{Group: group, Name: name, Status: Pass, Message: message}
+}
+
+func warn(group, name, message, remediation string) Result {
+	return Result{Group: group, Name: name, Status: Warn, Message: message, Remediation: remediation}
+}
+
+func fail(group, name, message, remediation string) Result {
+	return Result{Group: group, Name: name, Status: Fail, Message: message, Remediation: remediation}
+}
+
+func skip(group, name, reason string) Result {
+	return Result{Group: group, Name: name, Status: Skip, Message: reason}
+}
@@ -0,0 +1,158 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/auth"
+	"github.com/lorrehuggan/moodify/internal/config"
+)
+
+const groupLocal = "local"
+
+// Clock skew limits: tokens and TLS certificates start misbehaving well before
+// the clock is minutes out
+const (
+	skewWarn = 30 * time.Second
+	skewFail = 5 * time.Minute
+)
+
+// Local runs the checks of this machine: files, clock and ports. The clock is
+// compared with spotifyURL's.
+func Local(ctx context.Context, spotifyURL string) []Result {
+	results := []Result{configDir(), tokenFile(), clockSkew(ctx, spotifyURL)}
+	return append(results, ports(auth.CommonPorts)...)
+}
+
+// configDir checks that the configuration directory exists, is writable and
+// isn't writable by other users
+func configDir() Result {
+	const name = "config directory"
+
+	dir, err := config.Dir()
+	if err != nil {
+		return fail(groupLocal, name, err.Error(), "Make sure your user has a home directory")
+	}
+
+	info, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return warn(groupLocal, name, dir+" doesn't exist yet", "Run: moodify login")
+	}
+	if err != nil {
+		return fail(groupLocal, name, err.Error(), "Check the permissions of "+filepath.Dir(dir))
+	}
+	if !info.IsDir() {
+		return fail(groupLocal, name, dir+" is not a directory", "Move the file out of the way and run: moodify login")
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return fail(groupLocal, name, dir+" is not writable: "+err.Error(), "Run: chmod u+rwx "+dir)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	if mode := info.Mode().Perm(); mode&0o022 != 0 {
+		return warn(groupLocal, name, fmt.Sprintf("%s is writable by other users (%04o)", dir, mode), "Run: chmod go-w "+dir)
+	}
+	return pass(groupLocal, name, dir)
+}
+
+// tokenFile checks that the saved login exists, is private to its owner and
+// can be read
+func tokenFile() Result {
+	const name = "token file"
+
+	dir, err := config.Dir()
+	if err != nil {
+		return fail(groupLocal, name, err.Error(), "Make sure your user has a home directory")
+	}
+	path := filepath.Join(dir, auth.TokenFileName)
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fail(groupLocal, name, "not logged in", "Run: moodify login")
+	}
+	if err != nil {
+		return fail(groupLocal, name, err.Error(), "Check the permissions of "+dir)
+	}
+
+	status := auth.CheckToken()
+	switch status.State {
+	case auth.TokenMissing:
+		return fail(groupLocal, name, fmt.Sprintf("%s can't be read: %v", path, status.Err), "Run: moodify logout && moodify login")
+	case auth.TokenRefreshFailed:
+		return fail(groupLocal, name, "login expired: "+status.RefreshError, "Run: moodify login")
+	}
+
+	if mode := info.Mode().Perm(); mode&0o077 != 0 {
+		return warn(groupLocal, name, fmt.Sprintf("%s is readable by other users (%04o)", path, mode), "Run: chmod 600 "+path)
+	}
+	return pass(groupLocal, name, fmt.Sprintf("%s (%s)", path, status.State))
+}
+
+// clockSkew compares the local clock with the Date header of a response from
+// url
+func clockSkew(ctx context.Context, url string) Result {
+	const name = "clock"
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fail(groupLocal, name, err.Error(), "")
+	}
+	sent := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return warn(groupLocal, name, "couldn't reach Spotify to compare clocks: "+err.Error(), "Check your internet connection")
+	}
+	resp.Body.Close()
+
+	remote, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return warn(groupLocal, name, "Spotify's response had no usable Date header", "")
+	}
+	// The Date header has one-second resolution and was stamped mid-request
+	local := sent.Add(time.Since(sent) / 2)
+	skew := local.Sub(remote).Round(time.Second)
+
+	message := fmt.Sprintf("%s off Spotify's clock", skew.Abs())
+	switch {
+	case skew.Abs() >= skewFail:
+		return fail(groupLocal, name, message, "Turn on automatic date and time (NTP) in your system settings")
+	case skew.Abs() >= skewWarn:
+		return warn(groupLocal, name, message, "Turn on automatic date and time (NTP) in your system settings")
+	}
+	return pass(groupLocal, name, message)
+}
+
+// ports checks which of the login callback ports are free. Login uses the
+// first free one, so only all of them being taken is a failure.
+func ports(candidates []string) []Result {
+	results := make([]Result, 0, len(candidates))
+	free := 0
+	for _, port := range candidates {
+		name := "port " + port
+		if auth.PortAvailable(port) {
+			free++
+			results = append(results, pass(groupLocal, name, "free for the login callback"))
+			continue
+		}
+		results = append(results, warn(groupLocal, name, "in use", "moodify login tries the next port; or pick one with --port"))
+	}
+
+	if free == 0 {
+		for i := range results {
+			results[i].Status = Fail
+			results[i].Remediation = "Stop whatever is using these ports, or run: moodify login --port <free port>"
+		}
+	}
+	return results
+}
@@ -0,0 +1,150 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/auth"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	groupSpotify = "spotify"
+	groupAI      = "ai"
+)
+
+// checkTimeout bounds each check that talks to a server
+const checkTimeout = 20 * time.Second
+
+// Spotify runs the checks against the Spotify API, for the scopes in config.
+// They need a working login, so they are skipped when the token can't be
+// refreshed.
+func Spotify(ctx context.Context, config *auth.Config) []Result {
+	refresh := tokenRefresh(ctx, config)
+	results := []Result{refresh}
+
+	var client *spotify.Client
+	reason := "needs a working login"
+	if refresh.Status == Pass {
+		var err error
+		if client, err = auth.GetAuthenticatedClient(ctx, config); err != nil {
+			reason = err.Error()
+		}
+	}
+	if client == nil {
+		results = append(results, skip(groupSpotify, profileCheck, reason))
+		for _, scope := range config.Scopes {
+			results = append(results, skip(groupSpotify, scopeCheck(scope), reason))
+		}
+		return append(results, skip(groupSpotify, recommendationsCheck, reason))
+	}
+
+	results = append(results, profile(ctx, client))
+	results = append(results, scopes(config.Scopes)...)
+	return append(results, recommendations(ctx, client))
+}
+
+// remediation suggests a fix for err, falling back to checking the connection
+func remediation(err error) string {
+	if hint := errs.Describe(err).Hint; hint != "" {
+		return hint
+	}
+	return "Check your internet connection and try again"
+}
+
+// Names of the Spotify checks
+const (
+	refreshCheck         = "token refresh"
+	profileCheck         = "profile (/me)"
+	recommendationsCheck = "recommendations"
+)
+
+func scopeCheck(scope string) string {
+	return "scope " + scope
+}
+
+// tokenRefresh refreshes the saved token with Spotify, proving the refresh
+// token still works
+func tokenRefresh(ctx context.Context, config *auth.Config) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	token, err := auth.RefreshNow(ctx, config)
+	if err != nil {
+		return fail(groupSpotify, refreshCheck, err.Error(), remediation(err))
+	}
+	return pass(groupSpotify, refreshCheck, "new access token valid until "+token.Expiry.Format("15:04:05"))
+}
+
+// profile fetches the current user's profile (/v1/me)
+func profile(ctx context.Context, client *spotify.Client) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		err = errs.Classify(err)
+		return fail(groupSpotify, profileCheck, err.Error(), remediation(err))
+	}
+
+	message := fmt.Sprintf("%s account, market %s", user.Product, user.Country)
+	if user.Product != "premium" {
+		return warn(groupSpotify, profileCheck, message, "Playing and queueing tracks needs Spotify Premium; everything else works")
+	}
+	return pass(groupSpotify, profileCheck, message)
+}
+
+// scopes checks that the login was granted each of required
+func scopes(required []string) []Result {
+	granted, known, err := auth.GrantedScopes()
+	results := make([]Result, 0, len(required))
+	for _, scope := range required {
+		name := scopeCheck(scope)
+		switch {
+		case err != nil:
+			results = append(results, fail(groupSpotify, name, err.Error(), remediation(err)))
+		case !known:
+			results = append(results, warn(groupSpotify, name, "unknown: this login predates moodify recording scopes",
+				"Run 'moodify login' again so moodify can check its permissions"))
+		case slices.Contains(granted, scope):
+			results = append(results, pass(groupSpotify, name, "granted"))
+		default:
+			results = append(results, fail(groupSpotify, name, "not granted", errs.Describe(errs.ErrMissingScope).Hint))
+		}
+	}
+	return results
+}
+
+// recommendations checks that the recommendations endpoint answers. Search
+// and discover fall back to plain search without it, so failing is a warning.
+func recommendations(ctx context.Context, client *spotify.Client) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if _, err := client.GetRecommendations(ctx, spotify.Seeds{Genres: []string{"pop"}}, nil, spotify.Limit(1)); err != nil {
+		err = errs.Classify(err)
+		return warn(groupSpotify, recommendationsCheck, "unavailable: "+err.Error(),
+			"Search and discover fall back to keyword search, so results may be less tailored")
+	}
+	return pass(groupSpotify, recommendationsCheck, "reachable")
+}
+
+// AIProvider checks the OpenAI API key, when one is set
+func AIProvider(ctx context.Context) Result {
+	const name = "OpenAI"
+
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		return pass(groupAI, name, "not configured; using keyword parsing")
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if err := ai.Check(ctx); err != nil {
+		return fail(groupAI, name, err.Error(), remediation(err))
+	}
+	return pass(groupAI, name, "API key accepted")
+}
@@ -0,0 +1,161 @@
+// Package errs defines the kinds of failure moodify reports, each with an exit
+// code and a hint on how to fix it.
+package errs
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// Kinds of failure. Match them with errors.Is; errors returned by moodify wrap
+// them with the underlying cause.
+var (
+	ErrNotAuthenticated = errors.New("not authenticated")
+	ErrMissingScope     = errors.New("missing permission")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrNoActiveDevice   = errors.New("no active device")
+	ErrPremiumRequired  = errors.New("premium account required")
+	ErrNotFound         = errors.New("not found")
+	ErrAIUnavailable    = errors.New("AI parsing unavailable")
+)
+
+// Kind describes one kind of failure
+type Kind struct {
+	Err      error  // the sentinel, e.g. ErrNotAuthenticated
+	Code     string // machine-readable name, e.g. "not_authenticated"
+	ExitCode int
+	Hint     string // what the user can do about it
+}
+
+// ExitFailure is the exit code for failures that aren't one of the Kinds
+const ExitFailure = 1
+
+// Kinds lists every kind of failure, in the order they are matched
+var Kinds = []Kind{
+	{ErrNotAuthenticated, "not_authenticated", 3, "Run: moodify login"},
+	{ErrMissingScope, "missing_scope", 4, "Run 'moodify login' again to grant the permission this command needs"},
+	{ErrRateLimited, "rate_limited", 5, "Spotify is limiting requests; wait a minute and try again"},
+	{ErrNoActiveDevice, "no_active_device", 6, "Start Spotify on a device and try again"},
+	{ErrPremiumRequired, "premium_required", 7, "Controlling playback needs Spotify Premium"},
+	{ErrNotFound, "not_found", 8, "Check the name, ID or link and try again"},
+	{ErrAIUnavailable, "ai_unavailable", 9, "Check OPENAI_API_KEY and your OpenAI billing, or unset it to use keyword parsing"},
+}
+
+// classified is an error marked with its kind
+type classified struct {
+	kind error
+	err  error
+}
+
+func (e *classified) Error() string {
+	if e.err == nil {
+		return e.kind.Error()
+	}
+	return e.err.Error()
+}
+
+// Unwrap lets errors.Is and errors.As match both the kind and the cause
+func (e *classified) Unwrap() []error {
+	if e.err == nil {
+		return []error{e.kind}
+	}
+	return []error{e.kind, e.err}
+}
+
+// Wrap marks err as being of the given kind, keeping its message. A nil err
+// gives an error with the kind's own message.
+func Wrap(kind, err error) error {
+	return &classified{kind: kind, err: err}
+}
+
+// Classify marks Spotify API errors with their kind, going by status and
+// message. Other errors, and errors that already have a kind, are returned as is.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := kindOf(err); ok {
+		return err
+	}
+
+	var apiErr spotify.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	message := strings.ToLower(apiErr.Message)
+	switch apiErr.Status {
+	case http.StatusUnauthorized:
+		return Wrap(ErrNotAuthenticated, err)
+	case http.StatusForbidden:
+		switch {
+		case strings.Contains(message, "scope"):
+			return Wrap(ErrMissingScope, err)
+		case strings.Contains(message, "premium"):
+			return Wrap(ErrPremiumRequired, err)
+		}
+	case http.StatusNotFound:
+		if strings.Contains(message, "device") {
+			return Wrap(ErrNoActiveDevice, err)
+		}
+		return Wrap(ErrNotFound, err)
+	case http.StatusTooManyRequests:
+		return Wrap(ErrRateLimited, err)
+	}
+	return err
+}
+
+// ClassifyPlayback is Classify for player API calls, which answer 404 when
+// there is no device to control, whatever the message says
+func ClassifyPlayback(err error) error {
+	var apiErr spotify.Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		if _, ok := kindOf(err); !ok {
+			return Wrap(ErrNoActiveDevice, err)
+		}
+	}
+	return Classify(err)
+}
+
+// KindOf returns the kind of err, classifying Spotify API errors first
+func KindOf(err error) (Kind, bool) {
+	return kindOf(Classify(err))
+}
+
+func kindOf(err error) (Kind, bool) {
+	for _, kind := range Kinds {
+		if errors.Is(err, kind.Err) {
+			return kind, true
+		}
+	}
+	return Kind{}, false
+}
+
+// Details is a machine-readable description of a failure
+type Details struct {
+	Code     string `json:"code"` // a Kind's Code, or "error"
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// Describe explains err for users and scripts
+func Describe(err error) Details {
+	kind, ok := KindOf(err)
+	if !ok {
+		return Details{Code: "error", Message: err.Error(), ExitCode: ExitFailure}
+	}
+	return Details{Code: kind.Code, Message: err.Error(), Hint: kind.Hint, ExitCode: kind.ExitCode}
+}
+
+// WithHint formats err followed by its hint, for one-line status messages
+func WithHint(err error) string {
+	details := Describe(err)
+	if details.Hint == "" {
+		return details.Message
+	}
+	return details.Message + " - " + details.Hint
+}
@@ -0,0 +1,69 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func TestClassify(t *testing.T) {
+	apiError := func(status int, message string) error {
+		return fmt.Errorf("request failed: %w", spotify.Error{Status: status, Message: message})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error // nil when the error shouldn't get a kind
+	}{
+		{"expired token", apiError(http.StatusUnauthorized, "The access token expired"), ErrNotAuthenticated},
+		{"missing scope", apiError(http.StatusForbidden, "Insufficient client scope"), ErrMissingScope},
+		{"premium", apiError(http.StatusForbidden, "Player command failed: Premium required"), ErrPremiumRequired},
+		{"other forbidden", apiError(http.StatusForbidden, "Forbidden"), nil},
+		{"no device", apiError(http.StatusNotFound, "Player command failed: No active device found"), ErrNoActiveDevice},
+		{"not found", apiError(http.StatusNotFound, "Non existing id"), ErrNotFound},
+		{"rate limited", apiError(http.StatusTooManyRequests, "API rate limit exceeded"), ErrRateLimited},
+		{"server error", apiError(http.StatusBadGateway, "Bad gateway"), nil},
+		{"not from Spotify", errors.New("connection refused"), nil},
+		{"already classified", Wrap(ErrAIUnavailable, apiError(http.StatusNotFound, "")), ErrAIUnavailable},
+	}
+	for _, tt := range tests {
+		got := Classify(tt.err)
+		kind, ok := kindOf(got)
+		switch {
+		case tt.want == nil && ok:
+			t.Errorf("%s: classified as %s, want no kind", tt.name, kind.Code)
+		case tt.want != nil && (!ok || kind.Err != tt.want):
+			t.Errorf("%s: got %v, want %v", tt.name, kind.Err, tt.want)
+		}
+		if got.Error() != tt.err.Error() {
+			t.Errorf("%s: message changed to %q", tt.name, got.Error())
+		}
+	}
+
+	if Classify(nil) != nil {
+		t.Error("Classify(nil) should be nil")
+	}
+}
+
+func TestClassifyPlayback(t *testing.T) {
+	err := ClassifyPlayback(spotify.Error{Status: http.StatusNotFound, Message: "Not found."})
+	if !errors.Is(err, ErrNoActiveDevice) {
+		t.Errorf("a player 404 should mean no active device, got %v", err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	details := Describe(Wrap(ErrRateLimited, errors.New("slow down")))
+	if details.Code != "rate_limited" || details.ExitCode != 5 || details.Message != "slow down" || details.Hint == "" {
+		t.Errorf("unexpected details: %+v", details)
+	}
+
+	details = Describe(errors.New("boom"))
+	if details.Code != "error" || details.ExitCode != ExitFailure || details.Hint != "" {
+		t.Errorf("unexpected details for an unclassified error: %+v", details)
+	}
+}
@@ -0,0 +1,132 @@
+// Package fit picks tracks whose combined length matches a target duration.
+package fit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Selection methods
+const (
+	// BestFit finds the subset of tracks closest to the target (knapsack-style)
+	BestFit = "best-fit"
+	// FirstFit takes tracks in order, skipping any that would overshoot
+	FirstFit = "first-fit"
+)
+
+// Options controls how tracks are selected
+type Options struct {
+	Target    time.Duration
+	Tolerance time.Duration
+	Method    string
+}
+
+// Result is the outcome of a selection
+type Result struct {
+	// Indexes of the selected tracks, in their original order
+	Indexes []int
+	Total   time.Duration
+	// Within reports whether Total is within Tolerance of Target
+	Within bool
+}
+
+// Select chooses tracks with the given durations to fill opts.Target. The
+// total never exceeds Target + Tolerance.
+func Select(durations []time.Duration, opts Options) (Result, error) {
+	if opts.Target <= 0 {
+		return Result{}, fmt.Errorf("target duration must be positive")
+	}
+	if opts.Tolerance < 0 {
+		opts.Tolerance = 0
+	}
+
+	var indexes []int
+	switch opts.Method {
+	case FirstFit:
+		indexes = firstFit(durations, opts)
+	case BestFit, "":
+		indexes = bestFit(durations, opts)
+	default:
+		return Result{}, fmt.Errorf("unknown fit method %q (use %s or %s)", opts.Method, BestFit, FirstFit)
+	}
+
+	result := Result{Indexes: indexes}
+	for _, i := range indexes {
+		result.Total += durations[i]
+	}
+	gap := result.Total - opts.Target
+	if gap < 0 {
+		gap = -gap
+	}
+	result.Within = gap <= opts.Tolerance
+	return result, nil
+}
+
+// firstFit walks the tracks in order, adding each one that still fits, and
+// stops once the total is within tolerance of the target
+func firstFit(durations []time.Duration, opts Options) []int {
+	var indexes []int
+	var total time.Duration
+	for i, d := range durations {
+		if total >= opts.Target-opts.Tolerance {
+			break
+		}
+		if d <= 0 || total+d > opts.Target+opts.Tolerance {
+			continue
+		}
+		indexes = append(indexes, i)
+		total += d
+	}
+	return indexes
+}
+
+// bestFit solves a subset-sum over whole seconds for the total closest to the
+// target without exceeding target + tolerance. Among equally close subsets,
+// the one reached with earlier (higher ranked) tracks wins.
+func bestFit(durations []time.Duration, opts Options) []int {
+	limit := int((opts.Target + opts.Tolerance) / time.Second)
+	target := int(opts.Target / time.Second)
+
+	// from[s] is the track that first completed sum s, -1 if unreachable
+	from := make([]int, limit+1)
+	for s := range from {
+		from[s] = -1
+	}
+	from[0] = len(durations) // sentinel for the empty set
+	prev := make([]int, limit+1)
+
+	for i, d := range durations {
+		secs := int(d.Round(time.Second) / time.Second)
+		if secs <= 0 {
+			continue
+		}
+		// Iterate downwards so each track is used at most once
+		for s := limit; s >= secs; s-- {
+			if from[s] == -1 && from[s-secs] != -1 && from[s-secs] != i {
+				from[s] = i
+				prev[s] = s - secs
+			}
+		}
+	}
+
+	best := 0
+	for s := 0; s <= limit; s++ {
+		if from[s] != -1 && abs(s-target) < abs(best-target) {
+			best = s
+		}
+	}
+
+	// Walking back visits tracks from last to first; reverse into original order
+	var indexes []int
+	for s := best; s > 0; s = prev[s] {
+		indexes = append([]int{from[s]}, indexes...)
+	}
+	return indexes
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,151 @@
+// Package history keeps local, append-only records of listening activity and
+// of searches.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/config"
+)
+
+// PlaysFileName is the JSONL file plays are appended to
+const PlaysFileName = "history.jsonl"
+
+// Play is one completed listen recorded by `moodify history record`
+type Play struct {
+	TrackID     string    `json:"track_id"`
+	Track       string    `json:"track"`
+	Artists     []string  `json:"artists"`
+	ArtistIDs   []string  `json:"artist_ids,omitempty"`
+	Album       string    `json:"album"`
+	URI         string    `json:"uri"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+	ListenedMs  int       `json:"listened_ms"`
+	DurationMs  int       `json:"duration_ms"`
+	Device      string    `json:"device,omitempty"`
+	ContextType string    `json:"context_type,omitempty"` // playlist, album, artist...
+	ContextURI  string    `json:"context_uri,omitempty"`
+}
+
+// Artist returns the primary artist of the play
+func (p Play) Artist() string {
+	if len(p.Artists) == 0 {
+		return "Unknown Artist"
+	}
+	return p.Artists[0]
+}
+
+// Query selects plays from the store; zero values match everything
+type Query struct {
+	Since  time.Time
+	Until  time.Time
+	Artist string // case-insensitive substring of any artist
+	Track  string // case-insensitive substring of the track name
+	Limit  int    // keep only the most recent N plays
+}
+
+// Store is an append-only JSONL file of plays
+type Store struct {
+	path string
+}
+
+// Open returns the store in the moodify config directory
+func Open() (*Store, error) {
+	path, err := config.Path(PlaysFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: path}, nil
+}
+
+// Path returns the location of the store on disk
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append writes a play to the end of the store
+func (s *Store) Append(play Play) error {
+	data, err := json.Marshal(play)
+	if err != nil {
+		return fmt.Errorf("failed to encode play: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// Query returns matching plays, oldest first. A missing store has no plays.
+func (s *Store) Query(q Query) ([]Play, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	artist := strings.ToLower(q.Artist)
+	track := strings.ToLower(q.Track)
+
+	var plays []Play
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var play Play
+		if err := json.Unmarshal(line, &play); err != nil {
+			continue // skip lines damaged by an interrupted write
+		}
+
+		if !q.Since.IsZero() && play.EndedAt.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && play.EndedAt.After(q.Until) {
+			continue
+		}
+		if track != "" && !strings.Contains(strings.ToLower(play.Track), track) {
+			continue
+		}
+		if artist != "" && !matchesArtist(play.Artists, artist) {
+			continue
+		}
+
+		plays = append(plays, play)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	if q.Limit > 0 && len(plays) > q.Limit {
+		plays = plays[len(plays)-q.Limit:]
+	}
+	return plays, nil
+}
+
+func matchesArtist(artists []string, needle string) bool {
+	for _, a := range artists {
+		if strings.Contains(strings.ToLower(a), needle) {
+			return true
+		}
+	}
+	return false
+}
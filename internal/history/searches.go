@@ -0,0 +1,301 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/config"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/zmb3/spotify/v2"
+)
+
+// SearchesFileName is the JSONL file searches and discoveries are appended to
+const SearchesFileName = "searches.jsonl"
+
+// Commands a Search can come from
+const (
+	CommandSearch   = "search"
+	CommandDiscover = "discover"
+)
+
+// Seeds are the recommendation seeds a search used
+type Seeds struct {
+	Artists []string `json:"artists,omitempty"`
+	Tracks  []string `json:"tracks,omitempty"`
+	Genres  []string `json:"genres,omitempty"`
+}
+
+// Empty reports whether there are no seeds
+func (s Seeds) Empty() bool {
+	return len(s.Artists)+len(s.Tracks)+len(s.Genres) == 0
+}
+
+// Spotify converts the seeds for a recommendations request
+func (s Seeds) Spotify() spotify.Seeds {
+	seeds := spotify.Seeds{Genres: s.Genres}
+	for _, id := range s.Artists {
+		seeds.Artists = append(seeds.Artists, spotify.ID(id))
+	}
+	for _, id := range s.Tracks {
+		seeds.Tracks = append(seeds.Tracks, spotify.ID(id))
+	}
+	return seeds
+}
+
+// Search is one run of `moodify search` or `moodify discover`, with what's
+// needed to run it again or save its results
+type Search struct {
+	ID      int       `json:"id"`
+	At      time.Time `json:"at"`
+	Command string    `json:"command"` // CommandSearch or CommandDiscover
+
+	// Search: the query and what it was parsed into
+	Query   string      `json:"query,omitempty"`
+	Parser  string      `json:"parser,omitempty"` // "ai" or "keywords"
+	Filters *ai.Filters `json:"filters,omitempty"`
+
+	// Discover: the criteria flags given, and how tracks were found
+	Criteria map[string]string `json:"criteria,omitempty"`
+	Mode     string            `json:"mode,omitempty"`
+
+	Seeds Seeds `json:"seeds"`
+
+	// Options that shaped the results
+	Limit       int    `json:"limit,omitempty"`
+	Market      string `json:"market,omitempty"`
+	TargetMs    int64  `json:"target_ms,omitempty"` // --duration
+	ToleranceMs int64  `json:"tolerance_ms,omitempty"`
+	FitMethod   string `json:"fit_method,omitempty"`
+	Arc         string `json:"arc,omitempty"`
+	Sequence    string `json:"sequence,omitempty"`
+
+	TrackIDs []string `json:"track_ids"`
+	ReplayOf int      `json:"replay_of,omitempty"` // the search this re-ran
+}
+
+// maxSearches is how many searches the history keeps; older ones are dropped
+const maxSearches = 500
+
+// trimEvery is how often, in searches added, the history is trimmed to maxSearches
+const trimEvery = 50
+
+// ErrSearchNotFound is returned for search IDs that aren't in the history
+var ErrSearchNotFound = errs.Wrap(errs.ErrNotFound, errors.New("search not found"))
+
+// SearchStore is an append-only JSONL file of searches
+type SearchStore struct {
+	path string
+}
+
+// OpenSearches returns the search history in the moodify config directory
+func OpenSearches() (*SearchStore, error) {
+	path, err := config.Path(SearchesFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchStore{path: path}, nil
+}
+
+// Path returns the location of the store on disk
+func (s *SearchStore) Path() string {
+	return s.path
+}
+
+// valid reports whether a decoded line is a search moodify can show and re-run
+func (s *Search) valid() bool {
+	return s.ID > 0 && (s.Command == CommandSearch || s.Command == CommandDiscover)
+}
+
+// Add numbers the search after the last one, stamps it and appends it. Every
+// trimEvery searches the history is cut back to the last maxSearches.
+func (s *SearchStore) Add(search *Search) error {
+	last, err := s.last()
+	if err != nil {
+		return err
+	}
+	search.ID = 1
+	if last != nil {
+		search.ID = last.ID + 1
+	}
+	if search.At.IsZero() {
+		search.At = time.Now()
+	}
+
+	data, err := json.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("failed to encode search: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open search history: %w", err)
+	}
+	defer file.Close()
+
+	// Start a fresh line after a write that was cut off, so this one stays readable
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		end := make([]byte, 1)
+		if _, err := file.ReadAt(end, info.Size()-1); err == nil && end[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write search history: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write search history: %w", err)
+	}
+
+	if search.ID%trimEvery == 0 {
+		return s.trim()
+	}
+	return nil
+}
+
+// last returns the most recent search, reading back from the end of the file
+// in growing chunks rather than decoding the whole history
+func (s *SearchStore) last() (*Search, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open search history: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search history: %w", err)
+	}
+	size := info.Size()
+
+	for chunk := int64(64 * 1024); ; chunk *= 2 {
+		start := max(size-chunk, 0)
+		buf := make([]byte, size-start)
+		if _, err := file.ReadAt(buf, start); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read search history: %w", err)
+		}
+
+		lines := bytes.Split(buf, []byte{'\n'})
+		if start > 0 {
+			lines = lines[1:] // may start mid-line
+		}
+		for i := len(lines) - 1; i >= 0; i-- {
+			var search Search
+			if json.Unmarshal(lines[i], &search) == nil && search.valid() {
+				return &search, nil
+			}
+		}
+		if start == 0 {
+			return nil, nil
+		}
+	}
+}
+
+// trim rewrites the history with only the last maxSearches searches
+func (s *SearchStore) trim() error {
+	searches, err := s.List(0)
+	if err != nil || len(searches) <= maxSearches {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, search := range searches[len(searches)-maxSearches:] {
+		data, err := json.Marshal(search)
+		if err != nil {
+			return fmt.Errorf("failed to encode search: %w", err)
+		}
+		buf.Write(append(data, '\n'))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to trim search history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to trim search history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to trim search history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to trim search history: %w", err)
+	}
+	return nil
+}
+
+// List returns the most recent limit searches, oldest first; 0 returns all.
+// A missing store has no searches.
+func (s *SearchStore) List(limit int) ([]Search, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open search history: %w", err)
+	}
+	defer file.Close()
+
+	var searches []Search
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var search Search
+		if err := json.Unmarshal(line, &search); err != nil || !search.valid() {
+			continue // skip lines damaged by an interrupted write or a hand edit
+		}
+		searches = append(searches, search)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search history: %w", err)
+	}
+
+	if limit > 0 && len(searches) > limit {
+		searches = searches[len(searches)-limit:]
+	}
+	return searches, nil
+}
+
+// Get returns a search by ID, or the most recent one for "last"
+func (s *SearchStore) Get(ref string) (*Search, error) {
+	searches, err := s.List(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref == "last" {
+		if len(searches) == 0 {
+			return nil, fmt.Errorf("%w: the history is empty", ErrSearchNotFound)
+		}
+		return &searches[len(searches)-1], nil
+	}
+
+	id, err := strconv.Atoi(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search ID %q (use a number from 'moodify history searches', or last)", ref)
+	}
+	for i := range searches {
+		if searches[i].ID == id {
+			return &searches[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: #%d", ErrSearchNotFound, id)
+}
@@ -0,0 +1,116 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestSearchStore(t *testing.T) *SearchStore {
+	t.Helper()
+	return &SearchStore{path: filepath.Join(t.TempDir(), SearchesFileName)}
+}
+
+func TestSearchStoreAddNumbersAfterLast(t *testing.T) {
+	store := newTestSearchStore(t)
+	for i := 1; i <= 3; i++ {
+		search := &Search{Command: CommandSearch, Query: "chill"}
+		if err := store.Add(search); err != nil {
+			t.Fatal(err)
+		}
+		if search.ID != i {
+			t.Fatalf("search %d got ID %d", i, search.ID)
+		}
+	}
+
+	// A damaged last line and a record without a command are skipped
+	file, err := os.OpenFile(store.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"id":9,"command":""}` + "\n" + `{"id":10,"comm`)
+	file.Close()
+
+	search := &Search{Command: CommandDiscover}
+	if err := store.Add(search); err != nil {
+		t.Fatal(err)
+	}
+	if search.ID != 4 {
+		t.Fatalf("got ID %d after damaged lines, want 4", search.ID)
+	}
+
+	searches, err := store.List(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(searches) != 4 {
+		t.Fatalf("List returned %d searches, want 4", len(searches))
+	}
+}
+
+func TestSearchStoreTrims(t *testing.T) {
+	store := newTestSearchStore(t)
+	for i := 0; i < maxSearches+trimEvery; i++ {
+		if err := store.Add(&Search{Command: CommandSearch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	searches, err := store.List(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(searches) != maxSearches {
+		t.Fatalf("kept %d searches, want %d", len(searches), maxSearches)
+	}
+	if first, want := searches[0].ID, trimEvery+1; first != want {
+		t.Fatalf("oldest kept search is #%d, want #%d", first, want)
+	}
+
+	search := &Search{Command: CommandSearch}
+	if err := store.Add(search); err != nil {
+		t.Fatal(err)
+	}
+	if want := maxSearches + trimEvery + 1; search.ID != want {
+		t.Fatalf("got ID %d after trimming, want %d", search.ID, want)
+	}
+}
+
+func TestSearchStoreGet(t *testing.T) {
+	store := newTestSearchStore(t)
+	if _, err := store.Get("last"); err == nil {
+		t.Fatal("Get(last) on an empty history should fail")
+	}
+	for _, query := range []string{"sad", "happy"} {
+		if err := store.Add(&Search{Command: CommandSearch, Query: query}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		ref   string
+		query string
+		fails bool
+	}{
+		{"last", "happy", false},
+		{"1", "sad", false},
+		{"3", "", true},
+		{"abc", "", true},
+	}
+	for _, tt := range tests {
+		search, err := store.Get(tt.ref)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("Get(%q) should fail", tt.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Get(%q): %v", tt.ref, err)
+			continue
+		}
+		if search.Query != tt.query {
+			t.Errorf("Get(%q) = %q, want %q", tt.ref, search.Query, tt.query)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package history
+
+import (
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// Tracker turns a stream of player snapshots into completed plays
+type Tracker struct {
+	// MinListen is how long a track must be heard to count as a play
+	MinListen time.Duration
+
+	current  *Play
+	lastSeen time.Time
+	playing  bool
+	progress int
+}
+
+// Observe feeds a player snapshot (nil when nothing is playing) taken at now.
+// It returns the previous play once it has finished, or nil.
+func (t *Tracker) Observe(now time.Time, state *spotify.PlayerState) *Play {
+	t.listen(now)
+	t.lastSeen = now
+
+	if state == nil || state.Item == nil {
+		t.playing = false
+		return t.finish()
+	}
+
+	track := state.Item
+	progress := int(state.Progress)
+
+	// Same track: a jump back from past the halfway point means it repeated
+	if t.current != nil && t.current.TrackID == string(track.ID) {
+		repeated := progress+5000 < t.progress && t.progress > t.current.DurationMs/2
+		if !repeated {
+			t.playing = state.Playing
+			t.progress = progress
+			return nil
+		}
+	}
+
+	finished := t.finish()
+	t.start(now, state)
+	return finished
+}
+
+// Flush ends the current play, e.g. on shutdown, returning it if it counts
+func (t *Tracker) Flush(now time.Time) *Play {
+	t.listen(now)
+	t.playing = false
+	return t.finish()
+}
+
+// listen counts the time since the last snapshot if playback was running,
+// never more than the track's length
+func (t *Tracker) listen(now time.Time) {
+	if t.current == nil || !t.playing {
+		return
+	}
+	t.current.ListenedMs += int(now.Sub(t.lastSeen).Milliseconds())
+	if t.current.DurationMs > 0 && t.current.ListenedMs > t.current.DurationMs {
+		t.current.ListenedMs = t.current.DurationMs
+	}
+	t.current.EndedAt = now
+}
+
+func (t *Tracker) start(now time.Time, state *spotify.PlayerState) {
+	track := state.Item
+	play := &Play{
+		TrackID:     string(track.ID),
+		Track:       track.Name,
+		Album:       track.Album.Name,
+		URI:         string(track.URI),
+		StartedAt:   now.Add(-time.Duration(state.Progress) * time.Millisecond),
+		EndedAt:     now,
+		DurationMs:  int(track.Duration),
+		Device:      state.Device.Name,
+		ContextType: state.PlaybackContext.Type,
+		ContextURI:  string(state.PlaybackContext.URI),
+	}
+	for _, artist := range track.Artists {
+		play.Artists = append(play.Artists, artist.Name)
+		play.ArtistIDs = append(play.ArtistIDs, string(artist.ID))
+	}
+
+	t.current = play
+	t.playing = state.Playing
+	t.progress = int(state.Progress)
+}
+
+// finish clears the current play and returns it if it was listened to long enough
+func (t *Tracker) finish() *Play {
+	play := t.current
+	t.current = nil
+	t.progress = 0
+
+	if play == nil {
+		return nil
+	}
+
+	minListen := t.MinListen
+	if play.DurationMs > 0 && time.Duration(play.DurationMs)*time.Millisecond < minListen {
+		minListen = time.Duration(play.DurationMs) * time.Millisecond / 2
+	}
+	if time.Duration(play.ListenedMs)*time.Millisecond < minListen {
+		return nil
+	}
+	return play
+}
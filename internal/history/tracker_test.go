@@ -0,0 +1,55 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func playing(id string, durationMs, progressMs int) *spotify.PlayerState {
+	return &spotify.PlayerState{
+		CurrentlyPlaying: spotify.CurrentlyPlaying{
+			Playing:  true,
+			Progress: spotify.Numeric(progressMs),
+			Item: &spotify.FullTrack{SimpleTrack: spotify.SimpleTrack{
+				ID: spotify.ID(id), Name: id, Duration: spotify.Numeric(durationMs),
+			}},
+		},
+	}
+}
+
+func TestTrackerFlushClampsToDuration(t *testing.T) {
+	tracker := &Tracker{MinListen: 30 * time.Second}
+	start := time.Now()
+	tracker.Observe(start, playing("a", 60_000, 0))
+
+	// Asleep or disconnected long past the end of the track
+	play := tracker.Flush(start.Add(10 * time.Minute))
+	if play == nil {
+		t.Fatal("Flush dropped a play that was listened to in full")
+	}
+	if play.ListenedMs != 60_000 {
+		t.Fatalf("ListenedMs = %d, want the track's 60000", play.ListenedMs)
+	}
+}
+
+func TestTrackerObserve(t *testing.T) {
+	tracker := &Tracker{MinListen: 30 * time.Second}
+	start := time.Now()
+
+	if play := tracker.Observe(start, playing("a", 180_000, 0)); play != nil {
+		t.Fatalf("first snapshot returned a play: %+v", play)
+	}
+	if play := tracker.Observe(start.Add(10*time.Second), playing("b", 180_000, 0)); play != nil {
+		t.Fatalf("a 10s listen counted as a play: %+v", play)
+	}
+
+	play := tracker.Observe(start.Add(5*time.Minute), playing("c", 180_000, 0))
+	if play == nil || play.TrackID != "b" {
+		t.Fatalf("got %+v, want the play of b", play)
+	}
+	if play.ListenedMs != 180_000 {
+		t.Fatalf("ListenedMs = %d, want the track's 180000", play.ListenedMs)
+	}
+}
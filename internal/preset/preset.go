@@ -0,0 +1,200 @@
+// Package preset stores named moods: filters and seeds saved under a name so
+// search, discover and radio can reuse them.
+package preset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/config"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/lorrehuggan/moodify/internal/history"
+)
+
+// FileName is the file presets are kept in, inside the config directory
+const FileName = "presets.json"
+
+// Preset is a named mood
+type Preset struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Aliases     []string      `json:"aliases,omitempty"` // other names it answers to
+	Query       string        `json:"query,omitempty"`   // the vibe it was made from
+	Filters     ai.Filters    `json:"filters"`
+	Seeds       history.Seeds `json:"seeds"`
+	CreatedAt   *time.Time    `json:"created_at,omitempty"` // unset for the defaults
+}
+
+// ErrPresetNotFound is returned for names no preset answers to
+var ErrPresetNotFound = errs.Wrap(errs.ErrNotFound, errors.New("preset not found"))
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateName checks a preset name is a lowercase word, so it's easy to type
+// after --preset and --mood
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid preset name %q (use lowercase letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// Defaults are the moods moodify ships with. They're copied into the presets
+// file the first time it's written, and can be edited or deleted like any other.
+func Defaults() []Preset {
+	return []Preset{
+		{Name: "happy", Description: "Bright, upbeat and positive", Aliases: []string{"joyful", "uplifting"},
+			Filters: ai.Filters{MinValence: 0.7, MinEnergy: 0.5}},
+		{Name: "sad", Description: "Low and melancholy", Aliases: []string{"melancholy", "depressing"},
+			Filters: ai.Filters{MaxValence: 0.4, MaxEnergy: 0.6}},
+		{Name: "energetic", Description: "High energy and danceable", Aliases: []string{"pumped", "exciting"},
+			Filters: ai.Filters{MinEnergy: 0.7, MinDanceability: 0.6}},
+		{Name: "chill", Description: "Relaxed and calm", Aliases: []string{"relaxed", "calm"},
+			Filters: ai.Filters{MaxEnergy: 0.5, MinValence: 0.3}},
+		{Name: "angry", Description: "Intense and aggressive", Aliases: []string{"aggressive", "intense"},
+			Filters: ai.Filters{MinEnergy: 0.8, MaxValence: 0.4}},
+		{Name: "romantic", Description: "Warm and intimate", Aliases: []string{"love", "intimate"},
+			Filters: ai.Filters{MinValence: 0.5, MaxEnergy: 0.7, MinDanceability: 0.3}},
+	}
+}
+
+// Store is the presets file. Until it exists, the defaults are the presets.
+type Store struct {
+	path string
+}
+
+// Open returns the presets in the moodify config directory
+func Open() (*Store, error) {
+	path, err := config.Path(FileName)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: path}, nil
+}
+
+// Path returns the location of the store on disk
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns every preset, sorted by name
+func (s *Store) List() ([]Preset, error) {
+	var presets []Preset
+	data, err := os.ReadFile(s.path)
+	switch {
+	case os.IsNotExist(err):
+		presets = Defaults()
+	case err != nil:
+		return nil, fmt.Errorf("failed to read presets: %w", err)
+	default:
+		if err := json.Unmarshal(data, &presets); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+		}
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// Get returns the preset with a name or alias, ignoring case
+func (s *Store) Get(name string) (*Preset, error) {
+	presets, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i], nil
+		}
+	}
+	for i := range presets {
+		if slices.Contains(presets[i].Aliases, name) {
+			return &presets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q (see 'moodify preset list')", ErrPresetNotFound, name)
+}
+
+// Save adds a preset, replacing any with the same name. It reports whether
+// one was replaced. Names that are another preset's alias are refused.
+func (s *Store) Save(preset Preset) (bool, error) {
+	if err := ValidateName(preset.Name); err != nil {
+		return false, err
+	}
+	presets, err := s.List()
+	if err != nil {
+		return false, err
+	}
+
+	// A name that's another preset's alias would never be found by Get
+	for _, p := range presets {
+		if p.Name != preset.Name && slices.Contains(p.Aliases, preset.Name) {
+			return false, fmt.Errorf("%q already names preset '%s' as an alias; pick another name", preset.Name, p.Name)
+		}
+	}
+
+	replaced := false
+	for i := range presets {
+		if presets[i].Name == preset.Name {
+			presets[i], replaced = preset, true
+		}
+	}
+	if !replaced {
+		presets = append(presets, preset)
+	}
+	return replaced, s.write(presets)
+}
+
+// Delete removes the preset with a name or alias, as Get finds it, and
+// returns the deleted preset
+func (s *Store) Delete(name string) (*Preset, error) {
+	deleted, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	presets, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	presets = slices.DeleteFunc(presets, func(p Preset) bool { return p.Name == deleted.Name })
+	return deleted, s.write(presets)
+}
+
+func (s *Store) write(presets []Preset) error {
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode presets: %w", err)
+	}
+
+	// Replace the file in one step, so a crash can't leave it cut short
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write presets: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write presets: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write presets: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write presets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package preset
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/errs"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return &Store{path: filepath.Join(t.TempDir(), FileName)}
+}
+
+func TestStoreDefaults(t *testing.T) {
+	store := newTestStore(t)
+	presets, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != len(Defaults()) {
+		t.Fatalf("got %d presets before the file exists, want the %d defaults", len(presets), len(Defaults()))
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"happy", "happy"},
+		{" Chill ", "chill"},
+		{"uplifting", "happy"},
+		{"CALM", "chill"},
+	}
+	for _, tt := range tests {
+		p, err := store.Get(tt.name)
+		if err != nil {
+			t.Errorf("Get(%q): %v", tt.name, err)
+			continue
+		}
+		if p.Name != tt.want {
+			t.Errorf("Get(%q) = %s, want %s", tt.name, p.Name, tt.want)
+		}
+	}
+
+	if _, err := store.Get("nope"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("Get(nope) = %v, want a not found error", err)
+	}
+}
+
+func TestStoreSaveAndDelete(t *testing.T) {
+	store := newTestStore(t)
+
+	focus := Preset{Name: "focus", Query: "deep instrumental ambient", Filters: ai.Filters{MaxEnergy: 0.4}}
+	if replaced, err := store.Save(focus); err != nil || replaced {
+		t.Fatalf("Save(focus) = %v, %v; want a new preset", replaced, err)
+	}
+	focus.Filters.MaxEnergy = 0.3
+	if replaced, err := store.Save(focus); err != nil || !replaced {
+		t.Fatalf("Save(focus) again = %v, %v; want it replaced", replaced, err)
+	}
+
+	presets, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != len(Defaults())+1 {
+		t.Fatalf("got %d presets, want the defaults and focus", len(presets))
+	}
+	if p, err := store.Get("focus"); err != nil || p.Filters.MaxEnergy != 0.3 {
+		t.Fatalf("Get(focus) = %+v, %v", p, err)
+	}
+	if matches, _ := filepath.Glob(store.path + ".*"); len(matches) > 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+
+	for _, name := range []string{"Focus ", "joyful"} {
+		if _, err := store.Delete(name); err != nil {
+			t.Fatalf("Delete(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"focus", "happy", "joyful"} {
+		if _, err := store.Get(name); err == nil {
+			t.Errorf("Get(%q) still finds a deleted preset", name)
+		}
+	}
+	if _, err := store.Delete("happy"); !errors.Is(err, ErrPresetNotFound) {
+		t.Errorf("deleting twice = %v, want ErrPresetNotFound", err)
+	}
+
+	if _, err := store.Save(Preset{Name: "calm"}); err == nil {
+		t.Error("saving over chill's alias should fail")
+	}
+	if _, err := store.Save(Preset{Name: "Bad Name"}); err == nil {
+		t.Error("saving an invalid name should fail")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"focus": true, "late-night": true, "gym_2": true, "9am": true,
+		"": false, "Focus": false, "-x": false, "two words": false, "café": false,
+	} {
+		if err := ValidateName(name); (err == nil) != valid {
+			t.Errorf("ValidateName(%q) = %v, want valid %v", name, err, valid)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package radio tracks the state of an endless, mood-driven listening session:
+// what has been played, what is waiting in the queue and what is banned.
+package radio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// maxRecentSeeds is how many recently played tracks seed recommendations,
+// leaving room for genre seeds within Spotify's limit of five
+const maxRecentSeeds = 3
+
+// BanList holds tracks and artists that should never be queued
+type BanList struct {
+	tracks  map[spotify.ID]bool
+	artists map[string]bool
+}
+
+// ParseBans builds a ban list from entries that are either track IDs, URIs
+// or URLs, or artist names
+func ParseBans(entries []string) BanList {
+	bans := BanList{tracks: map[spotify.ID]bool{}, artists: map[string]bool{}}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if id, ok := spotifyx.ParseID(entry, "track"); ok {
+			bans.tracks[id] = true
+		} else {
+			bans.artists[strings.ToLower(entry)] = true
+		}
+	}
+	return bans
+}
+
+// LoadBans reads ban entries from a file, one per line. Blank lines and lines
+// starting with # are ignored, and a missing file is an empty list.
+func LoadBans(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ban list: %w", err)
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ban list: %w", err)
+	}
+	return entries, nil
+}
+
+// Len returns the number of banned tracks and artists
+func (b BanList) Len() int {
+	return len(b.tracks) + len(b.artists)
+}
+
+// Banned reports whether the track or any of its artists is banned
+func (b BanList) Banned(track spotify.SimpleTrack) bool {
+	if b.tracks[track.ID] {
+		return true
+	}
+	for _, artist := range track.Artists {
+		if b.artists[strings.ToLower(artist.Name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Session remembers what the radio has played and queued
+type Session struct {
+	bans    BanList
+	played  map[spotify.ID]bool
+	recent  []spotify.ID
+	pending map[spotify.ID]bool
+}
+
+// NewSession starts a session with the given ban list
+func NewSession(bans BanList) *Session {
+	return &Session{
+		bans:    bans,
+		played:  map[spotify.ID]bool{},
+		pending: map[spotify.ID]bool{},
+	}
+}
+
+// Played records a track as played. It returns false if it was already known.
+func (s *Session) Played(id spotify.ID) bool {
+	if id == "" || s.played[id] {
+		return false
+	}
+	s.played[id] = true
+	delete(s.pending, id)
+
+	s.recent = append(s.recent, id)
+	if len(s.recent) > maxRecentSeeds {
+		s.recent = s.recent[len(s.recent)-maxRecentSeeds:]
+	}
+	return true
+}
+
+// Queued records tracks the radio added to the queue
+func (s *Session) Queued(tracks []spotify.SimpleTrack) {
+	for _, t := range tracks {
+		s.pending[t.ID] = true
+	}
+}
+
+// Pending returns how many of the radio's tracks are still waiting in the
+// queue. Tracks that have left the queue without being seen playing (skipped,
+// or the queue was cleared) are forgotten.
+func (s *Session) Pending(queue []spotify.ID) int {
+	inQueue := make(map[spotify.ID]bool, len(queue))
+	for _, id := range queue {
+		inQueue[id] = true
+	}
+	for id := range s.pending {
+		if !inQueue[id] {
+			delete(s.pending, id)
+		}
+	}
+	return len(s.pending)
+}
+
+// ResetSeeds forgets the recently played tracks, so a new mood isn't pulled
+// back towards the old one
+func (s *Session) ResetSeeds() {
+	s.recent = nil
+}
+
+// Seeds builds recommendation seeds from up to two genres and the tracks
+// played most recently
+func (s *Session) Seeds(genres []string) spotify.Seeds {
+	var seeds spotify.Seeds
+	if len(genres) > 2 {
+		genres = genres[:2]
+	}
+	seeds.Genres = genres
+	seeds.Tracks = append(seeds.Tracks, s.recent...)
+	return seeds
+}
+
+// Pick chooses up to n tracks that haven't been played or queued this
+// session and aren't banned
+func (s *Session) Pick(tracks []spotify.SimpleTrack, n int) []spotify.SimpleTrack {
+	picked := make([]spotify.SimpleTrack, 0, n)
+	seen := map[spotify.ID]bool{}
+	for _, t := range tracks {
+		if len(picked) >= n {
+			break
+		}
+		if t.ID == "" || seen[t.ID] || s.played[t.ID] || s.pending[t.ID] || s.bans.Banned(t) {
+			continue
+		}
+		seen[t.ID] = true
+		picked = append(picked, t)
+	}
+	return picked
+}
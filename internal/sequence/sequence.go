@@ -0,0 +1,320 @@
+// Package sequence orders tracks for smooth, DJ-style transitions using
+// Camelot-wheel key compatibility, tempo proximity and energy flow.
+package sequence
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/lorrehuggan/moodify/internal/analysis"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Solvers for the ordering problem
+const (
+	MethodGreedy = "greedy" // nearest neighbour from the best starting track
+	MethodTwoOpt = "2opt"   // greedy followed by 2-opt improvement
+)
+
+// maxGreedyStarts caps how many starting tracks the greedy solver tries
+const maxGreedyStarts = 150
+
+// Track is a track to sequence. Index is its position in the caller's list,
+// so results can be mapped back to the original tracks.
+type Track struct {
+	Index    int
+	Name     string
+	Artist   string
+	Features *spotify.AudioFeatures
+}
+
+// Weights scale each part of the transition cost
+type Weights struct {
+	Key    float64
+	Tempo  float64
+	Energy float64
+}
+
+// DefaultWeights favour key compatibility and tempo over energy
+var DefaultWeights = Weights{Key: 1, Tempo: 1, Energy: 0.5}
+
+// CostFunc returns the cost of playing b straight after a; lower is smoother
+type CostFunc func(a, b *spotify.AudioFeatures) float64
+
+// Options controls how tracks are sequenced
+type Options struct {
+	Method  string
+	Weights Weights
+	// Cost overrides the weighted key/tempo/energy cost when set
+	Cost CostFunc
+}
+
+// Transition describes the move from one track to the next
+type Transition struct {
+	From        Track   `json:"-"`
+	To          Track   `json:"-"`
+	FromKey     string  `json:"from_key"`
+	ToKey       string  `json:"to_key"`
+	FromTempo   float64 `json:"from_tempo"`
+	ToTempo     float64 `json:"to_tempo"`
+	EnergyDelta float64 `json:"energy_delta"`
+	Cost        float64 `json:"cost"`
+	Quality     string  `json:"quality"`
+}
+
+// Result is a sequenced track list
+type Result struct {
+	// Tracks in their new order; tracks without audio features come last
+	Tracks       []Track
+	Transitions  []Transition
+	TotalCost    float64
+	OriginalCost float64
+	// Unsequenced counts tracks without audio features
+	Unsequenced int
+}
+
+// Weighted returns the default cost function: a weighted sum of key, tempo
+// and energy costs, each roughly between 0 (seamless) and 1 (jarring)
+func (w Weights) Weighted() CostFunc {
+	return func(a, b *spotify.AudioFeatures) float64 {
+		return w.Key*KeyCost(a, b) + w.Tempo*TempoCost(a, b) + w.Energy*EnergyCost(a, b)
+	}
+}
+
+// KeyCost scores how well two keys mix on the Camelot wheel
+func KeyCost(a, b *spotify.AudioFeatures) float64 {
+	switch analysis.CamelotDistance(int(a.Key), int(a.Mode), int(b.Key), int(b.Mode)) {
+	case -1:
+		return 0.5 // unknown key, assume an average mix
+	case 0:
+		return 0
+	case 1:
+		return 0.2
+	case 2:
+		return 0.6
+	default:
+		return 1
+	}
+}
+
+// TempoCost scores the tempo gap, treating half and double time as a match.
+// A 10% difference or more costs 1.
+func TempoCost(a, b *spotify.AudioFeatures) float64 {
+	if a.Tempo <= 0 || b.Tempo <= 0 {
+		return 0.5
+	}
+
+	from, to := float64(a.Tempo), float64(b.Tempo)
+	gap := math.Inf(1)
+	for _, ratio := range []float64{1, 2, 0.5} {
+		gap = math.Min(gap, math.Abs(to*ratio-from)/from)
+	}
+	return math.Min(gap*10, 1)
+}
+
+// EnergyCost scores the change in energy between two tracks
+func EnergyCost(a, b *spotify.AudioFeatures) float64 {
+	return math.Min(math.Abs(float64(b.Energy-a.Energy))*2, 1)
+}
+
+// Sequence orders tracks so that each transition is as smooth as possible
+func Sequence(tracks []Track, opts Options) (*Result, error) {
+	if opts.Method == "" {
+		opts.Method = MethodTwoOpt
+	}
+	if opts.Method != MethodGreedy && opts.Method != MethodTwoOpt {
+		return nil, fmt.Errorf("unknown sequencing method %q (use %s or %s)", opts.Method, MethodGreedy, MethodTwoOpt)
+	}
+	if opts.Cost == nil {
+		weights := opts.Weights
+		if weights == (Weights{}) {
+			weights = DefaultWeights
+		}
+		opts.Cost = weights.Weighted()
+	}
+
+	var withFeatures, without []Track
+	for _, t := range tracks {
+		if t.Features != nil {
+			withFeatures = append(withFeatures, t)
+		} else {
+			without = append(without, t)
+		}
+	}
+
+	n := len(withFeatures)
+	cost := make([][]float64, n)
+	for i := range cost {
+		cost[i] = make([]float64, n)
+		for j := range cost[i] {
+			if i != j {
+				cost[i][j] = opts.Cost(withFeatures[i].Features, withFeatures[j].Features)
+			}
+		}
+	}
+
+	identity := make([]int, n)
+	for i := range identity {
+		identity[i] = i
+	}
+
+	order := identity
+	if n > 2 {
+		order = greedy(cost)
+		if opts.Method == MethodTwoOpt {
+			order = twoOpt(order, cost)
+		}
+	}
+
+	result := &Result{
+		OriginalCost: pathCost(identity, cost),
+		TotalCost:    pathCost(order, cost),
+		Unsequenced:  len(without),
+	}
+	for _, i := range order {
+		result.Tracks = append(result.Tracks, withFeatures[i])
+	}
+	for k := 1; k < len(order); k++ {
+		from, to := withFeatures[order[k-1]], withFeatures[order[k]]
+		result.Transitions = append(result.Transitions, newTransition(from, to, cost[order[k-1]][order[k]]))
+	}
+	result.Tracks = append(result.Tracks, without...)
+
+	return result, nil
+}
+
+// greedy builds a path by always moving to the cheapest unvisited track,
+// trying several starting tracks and keeping the cheapest path
+func greedy(cost [][]float64) []int {
+	n := len(cost)
+	starts := make([]int, n)
+	for i := range starts {
+		starts[i] = i
+	}
+	if n > maxGreedyStarts {
+		// Prefer starting from tracks that are cheap to leave
+		sort.Slice(starts, func(a, b int) bool {
+			return minCost(cost[starts[a]]) < minCost(cost[starts[b]])
+		})
+		starts = starts[:maxGreedyStarts]
+	}
+
+	var best []int
+	bestCost := math.Inf(1)
+	for _, start := range starts {
+		path := []int{start}
+		visited := make([]bool, n)
+		visited[start] = true
+		for len(path) < n {
+			last := path[len(path)-1]
+			next := -1
+			for j := 0; j < n; j++ {
+				if !visited[j] && (next == -1 || cost[last][j] < cost[last][next]) {
+					next = j
+				}
+			}
+			visited[next] = true
+			path = append(path, next)
+		}
+
+		if c := pathCost(path, cost); c < bestCost {
+			best, bestCost = path, c
+		}
+	}
+	return best
+}
+
+// twoOpt repeatedly reverses sections of the path while that lowers its
+// cost. The path is open (it doesn't loop back to the start). Each reversal
+// is scored by the change in the edges it touches: the two edges around the
+// section and, since costs are asymmetric, every transition inside it, which
+// now runs the other way. Those inner sums grow with j, so a pass is O(n²).
+func twoOpt(path []int, cost [][]float64) []int {
+	path = append([]int(nil), path...)
+	n := len(path)
+
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n-1; i++ {
+			forward, backward := 0.0, 0.0
+			for j := i + 1; j < n; j++ {
+				forward += cost[path[j-1]][path[j]]
+				backward += cost[path[j]][path[j-1]]
+
+				delta := backward - forward
+				if i > 0 {
+					delta += cost[path[i-1]][path[j]] - cost[path[i-1]][path[i]]
+				}
+				if j < n-1 {
+					delta += cost[path[i]][path[j+1]] - cost[path[j]][path[j+1]]
+				}
+				if delta < -1e-9 {
+					reverse(path, i, j)
+					improved = true
+					// The sums no longer match the path, so move on to the next i
+					break
+				}
+			}
+		}
+	}
+	return path
+}
+
+func reverse(path []int, i, j int) {
+	for ; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+}
+
+func pathCost(path []int, cost [][]float64) float64 {
+	total := 0.0
+	for k := 1; k < len(path); k++ {
+		total += cost[path[k-1]][path[k]]
+	}
+	return total
+}
+
+func minCost(row []float64) float64 {
+	best := math.Inf(1)
+	for _, c := range row {
+		if c > 0 && c < best {
+			best = c
+		}
+	}
+	return best
+}
+
+// newTransition describes the move between two tracks
+func newTransition(from, to Track, cost float64) Transition {
+	a, b := from.Features, to.Features
+	return Transition{
+		From:        from,
+		To:          to,
+		FromKey:     analysis.CamelotCode(int(a.Key), int(a.Mode)),
+		ToKey:       analysis.CamelotCode(int(b.Key), int(b.Mode)),
+		FromTempo:   float64(a.Tempo),
+		ToTempo:     float64(b.Tempo),
+		EnergyDelta: float64(b.Energy - a.Energy),
+		Cost:        cost,
+		Quality:     quality(KeyCost(a, b), TempoCost(a, b), EnergyCost(a, b)),
+	}
+}
+
+// quality labels a transition by its worst component
+func quality(costs ...float64) string {
+	worst := 0.0
+	for _, c := range costs {
+		worst = math.Max(worst, c)
+	}
+	switch {
+	case worst <= 0.2:
+		return "smooth"
+	case worst <= 0.6:
+		return "good"
+	case worst < 1:
+		return "rough"
+	default:
+		return "clash"
+	}
+}
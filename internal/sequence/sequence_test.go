@@ -0,0 +1,116 @@
+package sequence
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func features(key, mode int, tempo, energy float64) *spotify.AudioFeatures {
+	return &spotify.AudioFeatures{Key: spotify.Numeric(key), Mode: spotify.Numeric(mode), Tempo: float32(tempo), Energy: float32(energy)}
+}
+
+func TestCosts(t *testing.T) {
+	tests := []struct {
+		name string
+		cost CostFunc
+		a, b *spotify.AudioFeatures
+		want float64
+	}{
+		{"same key", KeyCost, features(0, 1, 120, 0.5), features(0, 1, 120, 0.5), 0},
+		{"unknown key", KeyCost, features(-1, 1, 120, 0.5), features(0, 1, 120, 0.5), 0.5},
+		{"same tempo", TempoCost, features(0, 1, 120, 0.5), features(0, 1, 120, 0.5), 0},
+		{"double time", TempoCost, features(0, 1, 70, 0.5), features(0, 1, 140, 0.5), 0},
+		{"5% faster", TempoCost, features(0, 1, 100, 0.5), features(0, 1, 105, 0.5), 0.5},
+		{"far tempo", TempoCost, features(0, 1, 100, 0.5), features(0, 1, 150, 0.5), 1},
+		{"unknown tempo", TempoCost, features(0, 1, 0, 0.5), features(0, 1, 120, 0.5), 0.5},
+		{"energy step", EnergyCost, features(0, 1, 120, 0.5), features(0, 1, 120, 0.7), 0.4},
+		{"energy jump", EnergyCost, features(0, 1, 120, 0.1), features(0, 1, 120, 0.9), 1},
+	}
+	for _, tt := range tests {
+		if got := tt.cost(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: got %.3f, want %.3f", tt.name, got, tt.want)
+		}
+	}
+}
+
+// randomCosts returns an asymmetric cost matrix
+func randomCosts(rng *rand.Rand, n int) [][]float64 {
+	cost := make([][]float64, n)
+	for i := range cost {
+		cost[i] = make([]float64, n)
+		for j := range cost[i] {
+			if i != j {
+				cost[i][j] = rng.Float64()
+			}
+		}
+	}
+	return cost
+}
+
+func TestTwoOpt(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{3, 4, 8, 20, 40} {
+		for trial := 0; trial < 10; trial++ {
+			cost := randomCosts(rng, n)
+			start := greedy(cost)
+			path := twoOpt(start, cost)
+
+			sorted := slices.Clone(path)
+			slices.Sort(sorted)
+			for i, v := range sorted {
+				if v != i {
+					t.Fatalf("n=%d: %v isn't a permutation", n, path)
+				}
+			}
+			if pathCost(path, cost) > pathCost(start, cost)+1e-9 {
+				t.Fatalf("n=%d: 2-opt raised the cost from %.3f to %.3f", n, pathCost(start, cost), pathCost(path, cost))
+			}
+
+			// No single reversal should improve the result any further
+			for i := 0; i < n-1; i++ {
+				for j := i + 1; j < n; j++ {
+					candidate := slices.Clone(path)
+					reverse(candidate, i, j)
+					if pathCost(candidate, cost) < pathCost(path, cost)-1e-9 {
+						t.Fatalf("n=%d: reversing %d..%d still improves %.3f to %.3f",
+							n, i, j, pathCost(path, cost), pathCost(candidate, cost))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	tracks := []Track{
+		{Index: 0, Features: features(0, 1, 120, 0.9)},
+		{Index: 1},
+		{Index: 2, Features: features(7, 1, 121, 0.2)},
+		{Index: 3, Features: features(0, 1, 120, 0.8)},
+		{Index: 4, Features: features(7, 1, 120, 0.3)},
+	}
+
+	for _, method := range []string{MethodGreedy, MethodTwoOpt} {
+		result, err := Sequence(tracks, Options{Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Tracks) != len(tracks) || result.Tracks[len(tracks)-1].Index != 1 {
+			t.Fatalf("%s: tracks without features should come last, got %+v", method, result.Tracks)
+		}
+		if result.Unsequenced != 1 || len(result.Transitions) != 3 {
+			t.Fatalf("%s: got %d unsequenced and %d transitions", method, result.Unsequenced, len(result.Transitions))
+		}
+		if result.TotalCost > result.OriginalCost {
+			t.Fatalf("%s: sequencing raised the cost from %.3f to %.3f", method, result.OriginalCost, result.TotalCost)
+		}
+	}
+
+	if _, err := Sequence(tracks, Options{Method: "random"}); err == nil {
+		t.Fatal("an unknown method should fail")
+	}
+}
@@ -0,0 +1,126 @@
+// Package server has the HTTP plumbing behind `moodify serve`: JSON responses,
+// bearer-token authentication and request logging.
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+)
+
+// maxBodyBytes caps the size of request bodies
+const maxBodyBytes = 1 << 20
+
+// ErrorBody is the JSON body of a failed request
+type ErrorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"` // kind of failure, see package errs
+	Hint  string `json:"hint,omitempty"`
+}
+
+// WriteJSON writes v as the JSON response body with the given status
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// WriteError writes an ErrorBody with the given status
+func WriteError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, ErrorBody{Error: message})
+}
+
+// WriteFailure writes an ErrorBody describing err, with its kind and hint
+func WriteFailure(w http.ResponseWriter, status int, err error) {
+	details := errs.Describe(err)
+	WriteJSON(w, status, ErrorBody{Error: details.Message, Code: details.Code, Hint: details.Hint})
+}
+
+// DecodeJSON reads a JSON request body into v, rejecting unknown fields
+func DecodeJSON(r *http.Request, w http.ResponseWriter, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// GenerateToken returns a random token suitable for RequireToken
+func GenerateToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// RequireToken rejects requests that don't carry "Authorization: Bearer <token>"
+func RequireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, given, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="moodify"`)
+			WriteError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder remembers the status and size of a response for logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// LogRequests logs one structured line per request
+func LogRequests(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("query", r.URL.RawQuery),
+			slog.Int("status", status),
+			slog.Int("bytes", recorder.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote", r.RemoteAddr),
+		)
+	})
+}
@@ -0,0 +1,407 @@
+package spotify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/cache"
+)
+
+// CacheMode controls how cached API responses are used
+type CacheMode int
+
+const (
+	// CacheOn serves fresh responses from the cache and revalidates stale ones
+	CacheOn CacheMode = iota
+	// CacheRefresh ignores cached responses but stores the new ones
+	CacheRefresh
+	// CacheOff neither reads nor writes the cache
+	CacheOff
+)
+
+// ResponseCacheMode is the mode used by transports made with NewCachingTransport
+var ResponseCacheMode = CacheOn
+
+// responseCacheDir is the directory inside the cache directory holding responses
+const responseCacheDir = "http"
+
+// CacheHeader is added to responses to say how the cache handled them:
+// "hit", "revalidated" or "miss"
+const CacheHeader = "X-Moodify-Cache"
+
+// CacheRule is how long responses from an endpoint stay fresh
+type CacheRule struct {
+	Endpoint string // label shown by `moodify cache stats`
+	Prefix   string // path prefix the rule applies to
+	TTL      time.Duration
+}
+
+// CacheRules lists the endpoints whose responses are cached. The first rule
+// whose prefix matches wins; anything else, including all player endpoints,
+// always goes to Spotify.
+var CacheRules = []CacheRule{
+	{Endpoint: "audio-features", Prefix: "/v1/audio-features", TTL: 30 * 24 * time.Hour},
+	{Endpoint: "audio-analysis", Prefix: "/v1/audio-analysis", TTL: 30 * 24 * time.Hour},
+	{Endpoint: "genre-seeds", Prefix: "/v1/recommendations/available-genre-seeds", TTL: 7 * 24 * time.Hour},
+	{Endpoint: "artists", Prefix: "/v1/artists", TTL: 24 * time.Hour},
+	{Endpoint: "top", Prefix: "/v1/me/top/", TTL: 6 * time.Hour},
+	{Endpoint: "search", Prefix: "/v1/search", TTL: 10 * time.Minute},
+}
+
+// cacheRule returns the rule for a request, or false if it isn't cacheable
+func cacheRule(req *http.Request) (CacheRule, bool) {
+	if req.Method != http.MethodGet || req.URL == nil {
+		return CacheRule{}, false
+	}
+	for _, rule := range CacheRules {
+		if strings.HasPrefix(req.URL.Path, rule.Prefix) {
+			return rule, true
+		}
+	}
+	return CacheRule{}, false
+}
+
+// cachedResponse is the on-disk format of a cached response
+type cachedResponse struct {
+	URL      string      `json:"url"`
+	Endpoint string      `json:"endpoint"`
+	StoredAt time.Time   `json:"stored_at"`
+	Expires  time.Time   `json:"expires"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+// fresh reports whether the response can be served without asking Spotify
+func (c *cachedResponse) fresh(now time.Time) bool {
+	return now.Before(c.Expires)
+}
+
+// response rebuilds the HTTP response for req
+func (c *cachedResponse) response(req *http.Request, how string) *http.Response {
+	header := c.Header.Clone()
+	header.Set(CacheHeader, how)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Status, http.StatusText(c.Status)),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// cachingTransport serves Spotify API responses from the cache directory
+type cachingTransport struct {
+	base http.RoundTripper
+	mode CacheMode
+}
+
+// NewCachingTransport wraps base with the on-disk response cache, using
+// ResponseCacheMode. Only GET requests matching CacheRules are cached.
+func NewCachingTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cachingTransport{base: base, mode: ResponseCacheMode}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rule, ok := cacheRule(req)
+	if !ok || t.mode == CacheOff || hasCacheDirective(req.Header, "no-store") {
+		return t.base.RoundTrip(req)
+	}
+
+	path, err := responsePath(req.URL.String())
+	if err != nil {
+		return t.base.RoundTrip(req)
+	}
+
+	var stored *cachedResponse
+	if t.mode == CacheOn {
+		stored, _ = readCachedResponse(path)
+	}
+	if stored != nil && stored.fresh(time.Now()) && !hasCacheDirective(req.Header, "no-cache") {
+		return stored.response(req, "hit"), nil
+	}
+
+	// Ask Spotify whether a stale copy is still current
+	outgoing := req
+	if stored != nil {
+		outgoing = req.Clone(req.Context())
+		if etag := stored.Header.Get("ETag"); etag != "" {
+			outgoing.Header.Set("If-None-Match", etag)
+		}
+		if modified := stored.Header.Get("Last-Modified"); modified != "" {
+			outgoing.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && stored != nil {
+		resp.Body.Close()
+		// A 304 only carries headers that changed
+		for _, name := range []string{"Cache-Control", "ETag", "Last-Modified"} {
+			if values := resp.Header.Values(name); len(values) > 0 {
+				stored.Header[name] = values
+			}
+		}
+		stored.StoredAt = time.Now()
+		stored.Expires = expiresAt(stored.StoredAt, rule, stored.Header)
+		_ = writeCachedResponse(path, stored)
+		return stored.response(req, "revalidated"), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || hasCacheDirective(resp.Header, "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.Header.Set(CacheHeader, "miss")
+
+	now := time.Now()
+	_ = writeCachedResponse(path, &cachedResponse{
+		URL:      req.URL.String(),
+		Endpoint: rule.Endpoint,
+		StoredAt: now,
+		Expires:  expiresAt(now, rule, resp.Header),
+		Status:   resp.StatusCode,
+		Header:   resp.Header.Clone(),
+		Body:     body,
+	})
+	return resp, nil
+}
+
+// expiresAt works out when a response stops being fresh. The endpoint's TTL
+// applies unless Spotify asks for every use to be revalidated.
+//
+// max-age is deliberately not used: Spotify's max-age on Web API responses is
+// typically zero or a few seconds, which would turn the cache off, while the
+// TTLs are chosen per endpoint for how often its data really changes. Stale entries are still
+// revalidated with their ETag. private doesn't apply either, since this cache
+// belongs to one user and lives in their own directory; no-store is honoured
+// in RoundTrip.
+func expiresAt(now time.Time, rule CacheRule, header http.Header) time.Time {
+	if hasCacheDirective(header, "no-cache") || hasCacheDirective(header, "must-revalidate") {
+		return now
+	}
+	return now.Add(rule.TTL)
+}
+
+// hasCacheDirective reports whether a Cache-Control header contains directive
+func hasCacheDirective(header http.Header, directive string) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if strings.EqualFold(name, directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ResponseCacheDir returns the directory holding cached responses, creating it if needed
+func ResponseCacheDir() (string, error) {
+	dir, err := cache.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, responseCacheDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// responsePath is the file caching the response for a URL
+func responsePath(url string) (string, error) {
+	dir, err := ResponseCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+func readCachedResponse(path string) (*cachedResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c cachedResponse
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func writeCachedResponse(path string, c *cachedResponse) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached response: %w", err)
+	}
+
+	// Write to a temp file of our own first, so concurrent readers never see a
+	// partial entry and concurrent writers (under serve) don't share one
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+	return nil
+}
+
+// CacheEndpointStats describes the cached responses of one endpoint
+type CacheEndpointStats struct {
+	Endpoint string     `json:"endpoint"`
+	TTLSecs  int64      `json:"ttl_seconds"`
+	Entries  int        `json:"entries"`
+	Fresh    int        `json:"fresh"`
+	Bytes    int64      `json:"bytes"`
+	Oldest   *time.Time `json:"oldest,omitempty"` // nil when empty
+}
+
+// CacheStats summarises the response cache
+type CacheStats struct {
+	Dir       string               `json:"dir"`
+	Entries   int                  `json:"entries"`
+	Bytes     int64                `json:"bytes"`
+	Endpoints []CacheEndpointStats `json:"endpoints"`
+}
+
+// ResponseCacheStats reads every cached response and totals them per endpoint,
+// in CacheRules order
+func ResponseCacheStats() (*CacheStats, error) {
+	dir, err := ResponseCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Endpoints are listed in CacheRules order, then any left over from
+	// endpoints that are no longer cached
+	byEndpoint := map[string]*CacheEndpointStats{}
+	var order []string
+	endpointStats := func(name string) *CacheEndpointStats {
+		if e, ok := byEndpoint[name]; ok {
+			return e
+		}
+		e := &CacheEndpointStats{Endpoint: name}
+		byEndpoint[name] = e
+		order = append(order, name)
+		return e
+	}
+	for _, rule := range CacheRules {
+		endpointStats(rule.Endpoint).TTLSecs = int64(rule.TTL.Seconds())
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CacheStats{Dir: dir}
+	now := time.Now()
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		c, err := readCachedResponse(file)
+		if err != nil {
+			continue
+		}
+
+		endpoint := endpointStats(c.Endpoint)
+		endpoint.Entries++
+		endpoint.Bytes += info.Size()
+		if c.fresh(now) {
+			endpoint.Fresh++
+		}
+		if endpoint.Oldest == nil || c.StoredAt.Before(*endpoint.Oldest) {
+			storedAt := c.StoredAt
+			endpoint.Oldest = &storedAt
+		}
+		stats.Entries++
+		stats.Bytes += info.Size()
+	}
+
+	for _, name := range order {
+		stats.Endpoints = append(stats.Endpoints, *byEndpoint[name])
+	}
+	return stats, nil
+}
+
+// ClearResponseCache removes cached responses and returns how many were
+// removed. With expiredOnly, fresh responses are kept. Endpoints limits
+// clearing to those endpoints; empty means all.
+func ClearResponseCache(expiredOnly bool, endpoints ...string) (int, error) {
+	dir, err := ResponseCacheDir()
+	if err != nil {
+		return 0, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json*"))
+	if err != nil {
+		return 0, err
+	}
+
+	wanted := map[string]bool{}
+	for _, e := range endpoints {
+		wanted[e] = true
+	}
+
+	now := time.Now()
+	removed := 0
+	for _, file := range files {
+		if expiredOnly || len(wanted) > 0 {
+			c, err := readCachedResponse(file)
+			if err == nil {
+				if len(wanted) > 0 && !wanted[c.Endpoint] {
+					continue
+				}
+				if expiredOnly && c.fresh(now) {
+					continue
+				}
+			}
+		}
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,49 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// audioFeaturesBatchSize is the maximum number of IDs per audio-features request
+const audioFeaturesBatchSize = 100
+
+// GetAudioFeaturesBatch fetches audio features for any number of tracks,
+// batching requests 100 IDs at a time. Tracks without features are left out
+// of the returned map.
+func GetAudioFeaturesBatch(ctx context.Context, client *spotify.Client, ids []spotify.ID) (map[spotify.ID]*spotify.AudioFeatures, error) {
+	features := make(map[spotify.ID]*spotify.AudioFeatures, len(ids))
+
+	// Skip duplicates and IDs we already have
+	unique := make([]spotify.ID, 0, len(ids))
+	seen := make(map[spotify.ID]bool, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	for i := 0; i < len(unique); i += audioFeaturesBatchSize {
+		end := i + audioFeaturesBatchSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+
+		batch, err := client.GetAudioFeatures(ctx, unique[i:end]...)
+		if err != nil {
+			return features, fmt.Errorf("failed to get audio features (batch %d-%d): %w", i+1, end, err)
+		}
+
+		for _, f := range batch {
+			if f != nil {
+				features[f.ID] = f
+			}
+		}
+	}
+
+	return features, nil
+}
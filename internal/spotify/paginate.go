@@ -0,0 +1,163 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// defaultPageDelay is the minimum gap between page requests
+const defaultPageDelay = 100 * time.Millisecond
+
+// Page adapts one of Spotify's paging objects for Collect. Items returns the
+// items on the current page and Next loads the following page in place,
+// returning spotify.ErrNoMorePages after the last one. HasNext, when set,
+// reports whether there is a following page, so Collect can stop without
+// waiting to ask.
+type Page[T any] struct {
+	Items   func() []T
+	Next    func(ctx context.Context) error
+	HasNext func() bool
+}
+
+// PageOptions controls how Collect walks through pages
+type PageOptions[T any] struct {
+	// Max stops collecting once this many items have been kept (0 for all)
+	Max int
+	// Keep filters items; nil keeps everything
+	Keep func(T) bool
+	// Delay is the minimum gap between page requests (default 100ms)
+	Delay time.Duration
+}
+
+// Collect gathers items from the current page and every page after it by
+// following the Next links. Requests are spaced out by opts.Delay; retrying
+// rate-limited and failed requests is left to the client's transport.
+func Collect[T any](ctx context.Context, page Page[T], opts PageOptions[T]) ([]T, error) {
+	if opts.Delay <= 0 {
+		opts.Delay = defaultPageDelay
+	}
+
+	var items []T
+	for {
+		for _, item := range page.Items() {
+			if opts.Keep != nil && !opts.Keep(item) {
+				continue
+			}
+			items = append(items, item)
+			if opts.Max > 0 && len(items) >= opts.Max {
+				return items, nil
+			}
+		}
+
+		if page.HasNext != nil && !page.HasNext() {
+			return items, nil
+		}
+		select {
+		case <-ctx.Done():
+			return items, ctx.Err()
+		case <-time.After(opts.Delay):
+		}
+
+		err := page.Next(ctx)
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			return items, nil
+		}
+		if err != nil {
+			return items, err
+		}
+	}
+}
+
+// PlaylistPages pages through the user's playlists starting at page
+func PlaylistPages(client *spotify.Client, page *spotify.SimplePlaylistPage) Page[spotify.SimplePlaylist] {
+	return Page[spotify.SimplePlaylist]{
+		Items:   func() []spotify.SimplePlaylist { return page.Playlists },
+		Next:    func(ctx context.Context) error { return client.NextPage(ctx, page) },
+		HasNext: func() bool { return page.Next != "" },
+	}
+}
+
+// PlaylistItemPages pages through the items of a playlist starting at page
+func PlaylistItemPages(client *spotify.Client, page *spotify.PlaylistItemPage) Page[spotify.PlaylistItem] {
+	return Page[spotify.PlaylistItem]{
+		Items:   func() []spotify.PlaylistItem { return page.Items },
+		Next:    func(ctx context.Context) error { return client.NextPage(ctx, page) },
+		HasNext: func() bool { return page.Next != "" },
+	}
+}
+
+// SavedTrackPages pages through the user's liked songs starting at page
+func SavedTrackPages(client *spotify.Client, page *spotify.SavedTrackPage) Page[spotify.SavedTrack] {
+	return Page[spotify.SavedTrack]{
+		Items:   func() []spotify.SavedTrack { return page.Tracks },
+		Next:    func(ctx context.Context) error { return client.NextPage(ctx, page) },
+		HasNext: func() bool { return page.Next != "" },
+	}
+}
+
+// ArtistPages pages through a list of artists, such as the user's top artists
+func ArtistPages(client *spotify.Client, page *spotify.FullArtistPage) Page[spotify.FullArtist] {
+	return Page[spotify.FullArtist]{
+		Items:   func() []spotify.FullArtist { return page.Artists },
+		Next:    func(ctx context.Context) error { return client.NextPage(ctx, page) },
+		HasNext: func() bool { return page.Next != "" },
+	}
+}
+
+// TrackPages pages through a list of tracks, such as the user's top tracks
+func TrackPages(client *spotify.Client, page *spotify.FullTrackPage) Page[spotify.FullTrack] {
+	return Page[spotify.FullTrack]{
+		Items:   func() []spotify.FullTrack { return page.Tracks },
+		Next:    func(ctx context.Context) error { return client.NextPage(ctx, page) },
+		HasNext: func() bool { return page.Next != "" },
+	}
+}
+
+// SimpleTrackPages pages through a list of simplified tracks, such as an album's tracks
+func SimpleTrackPages(client *spotify.Client, page *spotify.SimpleTrackPage) Page[spotify.SimpleTrack] {
+	return Page[spotify.SimpleTrack]{
+		Items:   func() []spotify.SimpleTrack { return page.Tracks },
+		Next:    func(ctx context.Context) error { return client.NextPage(ctx, page) },
+		HasNext: func() bool { return page.Next != "" },
+	}
+}
+
+// SavedTracks returns up to max of the user's liked songs (0 for all of them)
+func SavedTracks(ctx context.Context, client *spotify.Client, max int) ([]spotify.SavedTrack, error) {
+	page, err := client.CurrentUsersTracks(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+	return Collect(ctx, SavedTrackPages(client, page), PageOptions[spotify.SavedTrack]{Max: max})
+}
+
+// TopArtists returns up to max of the user's top artists for a time range (0 for all)
+func TopArtists(ctx context.Context, client *spotify.Client, r spotify.Range, max int) ([]spotify.FullArtist, error) {
+	page, err := client.CurrentUsersTopArtists(ctx, spotify.Timerange(r), spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+	return Collect(ctx, ArtistPages(client, page), PageOptions[spotify.FullArtist]{Max: max})
+}
+
+// TopTracks returns up to max of the user's top tracks for a time range (0 for all)
+func TopTracks(ctx context.Context, client *spotify.Client, r spotify.Range, max int) ([]spotify.FullTrack, error) {
+	page, err := client.CurrentUsersTopTracks(ctx, spotify.Timerange(r), spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+	return Collect(ctx, TrackPages(client, page), PageOptions[spotify.FullTrack]{Max: max})
+}
+
+// AlbumTracks returns every track on an album along with the album itself
+func AlbumTracks(ctx context.Context, client *spotify.Client, id spotify.ID) (*spotify.FullAlbum, []spotify.SimpleTrack, error) {
+	album, err := client.GetAlbum(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	tracks, err := Collect(ctx, SimpleTrackPages(client, &album.Tracks), PageOptions[spotify.SimpleTrack]{})
+	return album, tracks, err
+}
@@ -0,0 +1,63 @@
+package spotify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// testPages pages through pages of ints
+func testPages(pages [][]int, loads *int) Page[int] {
+	current := 0
+	return Page[int]{
+		Items: func() []int { return pages[current] },
+		Next: func(context.Context) error {
+			if current == len(pages)-1 {
+				return spotify.ErrNoMorePages
+			}
+			current++
+			*loads++
+			return nil
+		},
+		HasNext: func() bool { return current < len(pages)-1 },
+	}
+}
+
+func TestCollect(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages [][]int
+		opts  PageOptions[int]
+		want  int
+		loads int
+	}{
+		{"one page", [][]int{{1, 2}}, PageOptions[int]{}, 2, 0},
+		{"all pages", [][]int{{1, 2}, {3}, {4, 5}}, PageOptions[int]{}, 5, 2},
+		{"max", [][]int{{1, 2}, {3}, {4, 5}}, PageOptions[int]{Max: 3}, 3, 1},
+		{"keep", [][]int{{1, 2}, {3, 4}}, PageOptions[int]{Keep: func(i int) bool { return i%2 == 0 }}, 2, 1},
+	}
+	for _, tt := range tests {
+		loads := 0
+		tt.opts.Delay = time.Millisecond
+		items, err := Collect(context.Background(), testPages(tt.pages, &loads), tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(items) != tt.want || loads != tt.loads {
+			t.Errorf("%s: got %d items after %d loads, want %d after %d", tt.name, len(items), loads, tt.want, tt.loads)
+		}
+	}
+}
+
+func TestCollectDoesNotWaitAfterLastPage(t *testing.T) {
+	loads := 0
+	start := time.Now()
+	if _, err := Collect(context.Background(), testPages([][]int{{1}}, &loads), PageOptions[int]{Delay: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Collect took %s on a single page", elapsed)
+	}
+}
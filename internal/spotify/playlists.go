@@ -0,0 +1,183 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/zmb3/spotify/v2"
+)
+
+// playlistBatchSize is the maximum number of tracks per add/remove request
+const playlistBatchSize = 100
+
+// playlistFields limits a playlist lookup to its details and track count,
+// leaving out the first page of tracks
+const playlistFields = "collaborative,description,external_urls,href,id,images,name,owner,public,snapshot_id,uri,tracks(href,total)"
+
+// spotifyIDPattern matches a bare base-62 Spotify ID
+var spotifyIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// ErrPlaylistNotFound is returned when no playlist matches a name
+var ErrPlaylistNotFound = errs.Wrap(errs.ErrNotFound, errors.New("playlist not found"))
+
+// AmbiguousPlaylistError is returned when a name matches several playlists
+type AmbiguousPlaylistError struct {
+	Ref     string
+	Matches []spotify.SimplePlaylist
+}
+
+func (e *AmbiguousPlaylistError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q matches %d playlists, use the playlist ID instead:", e.Ref, len(e.Matches))
+	for _, p := range e.Matches {
+		fmt.Fprintf(&b, "\n   • %s (by %s, %d tracks) - %s", p.Name, ownerName(p.Owner), p.Tracks.Total, p.ID)
+	}
+	return b.String()
+}
+
+// ParseID extracts an ID of the given kind ("playlist", "track", "album")
+// from a Spotify URI, open.spotify.com URL or bare ID
+func ParseID(ref, kind string) (spotify.ID, bool) {
+	ref = strings.TrimSpace(ref)
+
+	if prefix := "spotify:" + kind + ":"; strings.HasPrefix(ref, prefix) {
+		return spotify.ID(strings.TrimPrefix(ref, prefix)), true
+	}
+
+	if u, err := url.Parse(ref); err == nil && strings.HasSuffix(u.Host, "spotify.com") {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		// Paths look like /playlist/ID or /intl-de/playlist/ID
+		for i := 0; i+1 < len(parts); i++ {
+			if parts[i] == kind && spotifyIDPattern.MatchString(parts[i+1]) {
+				return spotify.ID(parts[i+1]), true
+			}
+		}
+		return "", false
+	}
+
+	if spotifyIDPattern.MatchString(ref) {
+		return spotify.ID(ref), true
+	}
+	return "", false
+}
+
+// ResolvePlaylist finds a playlist by ID, URI, URL or name. Names are
+// matched case-insensitively against the user's playlists, preferring exact
+// matches over partial ones; several matches return an AmbiguousPlaylistError.
+func ResolvePlaylist(ctx context.Context, client *spotify.Client, ref string) (*spotify.SimplePlaylist, error) {
+	if id, ok := ParseID(ref, "playlist"); ok {
+		playlist, err := client.GetPlaylist(ctx, id, spotify.Fields(playlistFields))
+		if err == nil {
+			// The full playlist's tracks shadow the simple playlist's count
+			simple := playlist.SimplePlaylist
+			simple.Tracks = spotify.PlaylistTracks{Endpoint: playlist.Tracks.Endpoint, Total: playlist.Tracks.Total}
+			return &simple, nil
+		}
+		// A 22 character name is a valid ID too, so fall back to name matching
+		if strings.Contains(ref, ":") || strings.Contains(ref, "/") {
+			return nil, fmt.Errorf("failed to get playlist: %w", err)
+		}
+	}
+
+	playlists, err := CurrentUserPlaylists(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(ref))
+	var exact, partial []spotify.SimplePlaylist
+	for _, p := range playlists {
+		lower := strings.ToLower(p.Name)
+		if lower == name {
+			exact = append(exact, p)
+		} else if strings.Contains(lower, name) {
+			partial = append(partial, p)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: no playlist named %q", ErrPlaylistNotFound, ref)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, &AmbiguousPlaylistError{Ref: ref, Matches: matches}
+	}
+}
+
+// CurrentUserPlaylists returns every playlist the user owns or follows
+func CurrentUserPlaylists(ctx context.Context, client *spotify.Client) ([]spotify.SimplePlaylist, error) {
+	page, err := client.CurrentUsersPlaylists(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlists: %w", err)
+	}
+
+	playlists, err := Collect(ctx, PlaylistPages(client, page), PageOptions[spotify.SimplePlaylist]{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlists: %w", err)
+	}
+	return playlists, nil
+}
+
+// PlaylistTracks returns every track in a playlist, skipping episodes and local files
+func PlaylistTracks(ctx context.Context, client *spotify.Client, id spotify.ID) ([]spotify.FullTrack, error) {
+	tracks, _, err := PlaylistTracksWithTotal(ctx, client, id)
+	return tracks, err
+}
+
+// PlaylistTracksWithTotal is PlaylistTracks, also returning how many items the
+// playlist has including the episodes and local files that were skipped
+func PlaylistTracksWithTotal(ctx context.Context, client *spotify.Client, id spotify.ID) ([]spotify.FullTrack, int, error) {
+	page, err := client.GetPlaylistItems(ctx, id, spotify.Limit(100))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get playlist tracks: %w", err)
+	}
+	total := int(page.Total)
+
+	items, err := Collect(ctx, PlaylistItemPages(client, page), PageOptions[spotify.PlaylistItem]{
+		Keep: func(item spotify.PlaylistItem) bool {
+			return item.Track.Track != nil && item.Track.Track.ID != ""
+		},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get playlist tracks: %w", err)
+	}
+
+	tracks := make([]spotify.FullTrack, 0, len(items))
+	for _, item := range items {
+		tracks = append(tracks, *item.Track.Track)
+	}
+	return tracks, total, nil
+}
+
+// AddTracksInBatches adds tracks to a playlist, 100 per request as Spotify requires
+func AddTracksInBatches(ctx context.Context, client *spotify.Client, playlistID spotify.ID, ids []spotify.ID) error {
+	for i := 0; i < len(ids); i += playlistBatchSize {
+		end := i + playlistBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		if _, err := client.AddTracksToPlaylist(ctx, playlistID, ids[i:end]...); err != nil {
+			return fmt.Errorf("failed to add tracks to playlist (batch %d-%d): %w", i+1, end, err)
+		}
+	}
+	return nil
+}
+
+func ownerName(owner spotify.User) string {
+	if owner.DisplayName != "" {
+		return owner.DisplayName
+	}
+	return owner.ID
+}
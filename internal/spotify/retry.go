@@ -0,0 +1,262 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+)
+
+// ErrRequestBudget is returned once a transport has sent its budget of
+// requests. It's moodify's own safety limit, not Spotify rate limiting us, so
+// it has no kind.
+var ErrRequestBudget = errors.New("this command made too many Spotify requests; try it on fewer tracks or a smaller --limit")
+
+// RateLimitError is returned when Spotify is still rate limiting a request
+// after the retries, or asks for a longer wait than MaxDelay. Callers that poll
+// should wait at least RetryAfter before trying again.
+type RateLimitError struct {
+	// RetryAfter is the wait Spotify asked for; 0 if it didn't say
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("Spotify is rate limiting requests for %s", e.RetryAfter.Round(time.Second))
+	}
+	return "Spotify is rate limiting requests"
+}
+
+// Unwrap gives the error the rate-limited kind
+func (e *RateLimitError) Unwrap() error {
+	return errs.ErrRateLimited
+}
+
+// RetryAfter returns the wait a RateLimitError in err's chain asks for, or 0
+func RetryAfter(err error) time.Duration {
+	var limited *RateLimitError
+	if errors.As(err, &limited) {
+		return limited.RetryAfter
+	}
+	return 0
+}
+
+// RetryPolicy controls how failed Spotify requests are retried
+type RetryPolicy struct {
+	// MaxRetries is how many times one request is retried
+	MaxRetries int
+	// BaseDelay is the first backoff after a 5xx or network error; it doubles
+	// with every retry and is jittered
+	BaseDelay time.Duration
+	// MaxDelay caps a single wait, including Retry-After. A rate limit asking
+	// for a longer wait is returned instead of waited out.
+	MaxDelay time.Duration
+	// AttemptTimeout bounds each attempt, within the request's own deadline
+	AttemptTimeout time.Duration
+	// Budget is the most requests the transport sends, counting retries; 0 is unlimited
+	Budget int
+	// Logf, when set, is told about every retry decision
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultRetryPolicy is the policy used by transports made with NewRetryingTransport
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     4,
+	BaseDelay:      500 * time.Millisecond,
+	MaxDelay:       30 * time.Second,
+	AttemptTimeout: 30 * time.Second,
+	Budget:         1000,
+}
+
+// retryingTransport retries rate-limited and transiently failing requests
+type retryingTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	sent   atomic.Int64
+}
+
+// NewRetryingTransport wraps base so that 429 responses wait for Retry-After,
+// and 5xx responses and network errors back off exponentially, using
+// DefaultRetryPolicy. Only idempotent requests are retried after a 5xx or
+// network error, since Spotify may already have acted on them.
+func NewRetryingTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryingTransport{base: base, policy: DefaultRetryPolicy}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if t.policy.Budget > 0 && t.sent.Add(1) > int64(t.policy.Budget) {
+			return nil, fmt.Errorf("%w (limit %d)", ErrRequestBudget, t.policy.Budget)
+		}
+
+		resp, err := t.attempt(req, attempt)
+		if ctx.Err() != nil {
+			// The caller gave up; report what we have
+			return resp, err
+		}
+
+		wait, reason, retry := t.decide(req, resp, err, attempt)
+		if !retry {
+			if reason != "" {
+				t.logf("%s %s: %s, giving up", req.Method, req.URL.Path, reason)
+			}
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				// Hand the wait to the caller, which the status alone would lose
+				wait, _ := retryAfter(resp.Header)
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				return nil, &RateLimitError{RetryAfter: wait}
+			}
+			return resp, err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			t.logf("%s %s: %s, no time left to retry", req.Method, req.URL.Path, reason)
+			return resp, err
+		}
+		if resp != nil {
+			// Drain so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		t.logf("%s %s: %s, retrying in %s (attempt %d of %d)",
+			req.Method, req.URL.Path, reason, wait.Round(time.Millisecond), attempt+2, t.policy.MaxRetries+1)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt sends one copy of req, bounded by AttemptTimeout
+func (t *retryingTransport) attempt(req *http.Request, n int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.policy.AttemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), t.policy.AttemptTimeout)
+	}
+
+	out := req.Clone(ctx)
+	if n > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		out.Body = body
+	}
+
+	resp, err := t.base.RoundTrip(out)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The attempt's context must live until the body has been read
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// decide works out whether and how long to wait before retrying. reason
+// describes the failure, and is empty when the request succeeded.
+func (t *retryingTransport) decide(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, string, bool) {
+	var reason string
+	switch {
+	case err != nil:
+		reason = err.Error()
+	case resp.StatusCode == http.StatusTooManyRequests:
+		reason = "rate limited"
+	case resp.StatusCode >= 500:
+		reason = resp.Status
+	default:
+		return 0, "", false
+	}
+
+	if attempt >= t.policy.MaxRetries {
+		return 0, reason, false
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return 0, reason + " (request body can't be resent)", false
+	}
+
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		// Nothing was done, so any request can be retried
+		wait, ok := retryAfter(resp.Header)
+		if !ok {
+			wait = t.backoff(attempt)
+		}
+		if wait > t.policy.MaxDelay {
+			return 0, fmt.Sprintf("rate limited for %s", wait.Round(time.Second)), false
+		}
+		return wait, reason, true
+	}
+
+	if !idempotent(req.Method) {
+		return 0, reason, false
+	}
+	return t.backoff(attempt), reason, true
+}
+
+// backoff is the jittered exponential delay before retry number attempt+1:
+// somewhere between half and all of BaseDelay * 2^attempt, capped at MaxDelay
+func (t *retryingTransport) backoff(attempt int) time.Duration {
+	delay := t.policy.BaseDelay << attempt
+	if delay <= 0 || delay > t.policy.MaxDelay {
+		delay = t.policy.MaxDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (t *retryingTransport) logf(format string, args ...interface{}) {
+	if t.policy.Logf != nil {
+		t.policy.Logf(format, args...)
+	}
+}
+
+// retryAfter reads a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	raw := header.Get("Retry-After")
+	if raw == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(raw); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(raw); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// idempotent reports whether repeating a request with this method is safe
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// cancelOnClose releases an attempt's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
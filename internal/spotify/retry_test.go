@@ -0,0 +1,141 @@
+package spotify
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+)
+
+func testPolicy() RetryPolicy {
+	return RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.header != "" {
+			header.Set("Retry-After", tt.header)
+		}
+		got, ok := retryAfter(header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %v; want %s, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if got, ok := retryAfter(header); !ok || got <= 55*time.Second || got > time.Minute {
+		t.Errorf("retryAfter(date in a minute) = %s, %v", got, ok)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := &retryingTransport{policy: testPolicy()}
+	for attempt, full := range []time.Duration{100, 200, 400, 800} {
+		full *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if got := transport.backoff(attempt); got < full/2 || got > full {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", attempt, got, full/2, full)
+			}
+		}
+	}
+	// Large attempts are capped rather than overflowing
+	for _, attempt := range []int{10, 70} {
+		if got := transport.backoff(attempt); got < 2500*time.Millisecond || got > 5*time.Second {
+			t.Errorf("backoff(%d) = %s, want capped at MaxDelay", attempt, got)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	transport := &retryingTransport{policy: testPolicy()}
+	response := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Status: http.StatusText(status), Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+	get, _ := http.NewRequest(http.MethodGet, "https://api.spotify.com/v1/me", nil)
+	post, _ := http.NewRequest(http.MethodPost, "https://api.spotify.com/v1/me/player/next", nil)
+	unreplayable, _ := http.NewRequest(http.MethodPut, "https://api.spotify.com/v1/me/player/play", strings.NewReader("{}"))
+	unreplayable.GetBody = nil
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		resp    *http.Response
+		err     error
+		attempt int
+		retry   bool
+		wait    time.Duration // checked when set
+	}{
+		{"success", get, response(200, ""), nil, 0, false, 0},
+		{"not found", get, response(404, ""), nil, 0, false, 0},
+		{"rate limited", get, response(429, "2"), nil, 0, true, 2 * time.Second},
+		{"rate limited post", post, response(429, "1"), nil, 0, true, time.Second},
+		{"rate limited too long", get, response(429, "60"), nil, 0, false, 0},
+		{"server error", get, response(503, ""), nil, 1, true, 0},
+		{"server error post", post, response(502, ""), nil, 0, false, 0},
+		{"network error", get, nil, errors.New("connection reset"), 0, true, 0},
+		{"out of retries", get, response(503, ""), nil, 3, false, 0},
+		{"body can't be resent", unreplayable, response(503, ""), nil, 0, false, 0},
+	}
+	for _, tt := range tests {
+		wait, reason, retry := transport.decide(tt.req, tt.resp, tt.err, tt.attempt)
+		if retry != tt.retry {
+			t.Errorf("%s: retry = %v (%s), want %v", tt.name, retry, reason, tt.retry)
+		}
+		if tt.wait > 0 && wait != tt.wait {
+			t.Errorf("%s: wait = %s, want %s", tt.name, wait, tt.wait)
+		}
+		if tt.name != "success" && tt.name != "not found" && reason == "" {
+			t.Errorf("%s: no reason given", tt.name)
+		}
+	}
+}
+
+// roundTripFunc is an http.RoundTripper made from a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRoundTripReturnsLongRateLimits(t *testing.T) {
+	sent := 0
+	transport := &retryingTransport{policy: testPolicy(), base: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		sent++
+		header := http.Header{}
+		header.Set("Retry-After", "120")
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header, Body: http.NoBody}, nil
+	})}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.spotify.com/v1/me/player", nil)
+	resp, err := transport.RoundTrip(req)
+	if resp != nil || sent != 1 {
+		t.Fatalf("got a response after %d requests, want the error after 1", sent)
+	}
+	if got := RetryAfter(err); got != 2*time.Minute {
+		t.Fatalf("RetryAfter = %s (%v), want 2m", got, err)
+	}
+	if !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("%v isn't classified as rate limited", err)
+	}
+}
@@ -2,7 +2,10 @@ package spotify
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/zmb3/spotify/v2"
 )
@@ -70,3 +73,112 @@ func GetRecommendationsWithFilters(ctx context.Context, client *spotify.Client,
 	return client.GetRecommendations(ctx, seeds, opts,
 		spotify.Limit(limit), spotify.Market(market))
 }
+
+// GetRecommendationsForTarget gets recommendations close to a target energy
+// and tempo, allowing energy to drift by up to energyRange either way
+func GetRecommendationsForTarget(ctx context.Context, client *spotify.Client, seeds spotify.Seeds,
+	energy, energyRange, tempo float64,
+	minPopularity int,
+	limit int, market string) (*spotify.Recommendations, error) {
+
+	opts := spotify.NewTrackAttributes().
+		TargetEnergy(energy).
+		MinEnergy(math.Max(energy-energyRange, 0)).
+		MaxEnergy(math.Min(energy+energyRange, 1))
+
+	if tempo > 0 {
+		opts = opts.TargetTempo(tempo)
+	}
+	if minPopularity > 0 {
+		opts = opts.MinPopularity(minPopularity)
+	}
+
+	return client.GetRecommendations(ctx, seeds, opts,
+		spotify.Limit(limit), spotify.Market(market))
+}
+
+// GetRecommendationsForTempo gets recommendations within tempoRange BPM of a
+// target tempo, optionally constrained to an energy range
+func GetRecommendationsForTempo(ctx context.Context, client *spotify.Client, seeds spotify.Seeds,
+	tempo, tempoRange float64,
+	minEnergy, maxEnergy float64,
+	minPopularity int,
+	limit int, market string) (*spotify.Recommendations, error) {
+
+	opts := spotify.NewTrackAttributes().
+		TargetTempo(tempo).
+		MinTempo(math.Max(tempo-tempoRange, 0)).
+		MaxTempo(tempo + tempoRange)
+
+	if minEnergy > 0 {
+		opts = opts.MinEnergy(minEnergy)
+	}
+	if maxEnergy > 0 && maxEnergy < 1 {
+		opts = opts.MaxEnergy(maxEnergy)
+	}
+	if minPopularity > 0 {
+		opts = opts.MinPopularity(minPopularity)
+	}
+
+	return client.GetRecommendations(ctx, seeds, opts,
+		spotify.Limit(limit), spotify.Market(market))
+}
+
+// artistBatchSize is the most artists Spotify returns in one request
+const artistBatchSize = 50
+
+// ExcludeArtistGenres drops tracks whose artists are tagged with any of the
+// given genres. Genres match on whole words, so "hip-hop" also excludes
+// "uk hip hop" but "rap" doesn't exclude "trap".
+func ExcludeArtistGenres(ctx context.Context, client *spotify.Client, tracks []spotify.SimpleTrack, genres []string) ([]spotify.SimpleTrack, error) {
+	normalize := func(s string) string {
+		return " " + strings.ReplaceAll(strings.ToLower(s), "-", " ") + " "
+	}
+
+	var ids []spotify.ID
+	seen := map[spotify.ID]bool{}
+	for _, t := range tracks {
+		for _, a := range t.Artists {
+			if a.ID != "" && !seen[a.ID] {
+				seen[a.ID] = true
+				ids = append(ids, a.ID)
+			}
+		}
+	}
+
+	excluded := map[spotify.ID]bool{}
+	for start := 0; start < len(ids); start += artistBatchSize {
+		end := min(start+artistBatchSize, len(ids))
+		artists, err := client.GetArtists(ctx, ids[start:end]...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get artist genres: %w", err)
+		}
+		for _, artist := range artists {
+			if artist == nil {
+				continue
+			}
+			for _, g := range artist.Genres {
+				for _, ex := range genres {
+					if strings.Contains(normalize(g), normalize(ex)) {
+						excluded[artist.ID] = true
+					}
+				}
+			}
+		}
+	}
+
+	kept := make([]spotify.SimpleTrack, 0, len(tracks))
+	for _, t := range tracks {
+		keep := true
+		for _, a := range t.Artists {
+			if excluded[a.ID] {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			kept = append(kept, t)
+		}
+	}
+	return kept, nil
+}
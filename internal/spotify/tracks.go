@@ -0,0 +1,36 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// tracksBatchSize is the maximum number of IDs per tracks request
+const tracksBatchSize = 50
+
+// GetTracksBatch looks up any number of tracks by ID, keeping their order and
+// batching requests 50 IDs at a time. Tracks Spotify no longer has are left out.
+func GetTracksBatch(ctx context.Context, client *spotify.Client, ids []spotify.ID) ([]spotify.SimpleTrack, error) {
+	tracks := make([]spotify.SimpleTrack, 0, len(ids))
+	for i := 0; i < len(ids); i += tracksBatchSize {
+		end := min(i+tracksBatchSize, len(ids))
+
+		batch, err := client.GetTracks(ctx, ids[i:end])
+		if err != nil {
+			return tracks, fmt.Errorf("failed to get tracks (batch %d-%d): %w", i+1, end, err)
+		}
+
+		for _, t := range batch {
+			if t == nil {
+				continue
+			}
+			// The full track's album shadows the simple track's
+			track := t.SimpleTrack
+			track.Album = t.Album
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
@@ -0,0 +1,97 @@
+package spotify
+
+import (
+	"context"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// WatchOptions controls how often Watch polls the player
+type WatchOptions struct {
+	// Interval between polls while something is playing
+	Interval time.Duration
+	// MaxInterval caps the backoff used while paused, idle or failing
+	MaxInterval time.Duration
+	// FullState polls the full player state (device, shuffle, repeat) rather
+	// than just the currently playing track
+	FullState bool
+	// OnError is called when a poll fails, with the delay before the next attempt
+	OnError func(err error, retryIn time.Duration)
+}
+
+// Watch polls the currently playing track until ctx is cancelled, calling
+// onUpdate with every snapshot. The snapshot is nil when nothing is playing;
+// its device fields are only set when FullState is enabled.
+//
+// Polling backs off exponentially (up to MaxInterval) while playback is paused
+// or requests fail, and returns to Interval as soon as playback resumes.
+// Rate-limited requests are retried by the transport after the Retry-After
+// delay; when Spotify asks for a longer wait than it will take, Watch waits
+// that long before polling again, even beyond MaxInterval.
+func Watch(ctx context.Context, client *spotify.Client, opts WatchOptions, onUpdate func(*spotify.PlayerState)) error {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	if opts.MaxInterval < opts.Interval {
+		opts.MaxInterval = 30 * time.Second
+		if opts.MaxInterval < opts.Interval {
+			opts.MaxInterval = opts.Interval
+		}
+	}
+
+	delay := opts.Interval
+	for {
+		current, err := pollPlayer(ctx, client, opts.FullState)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			delay = backoff(delay, opts.MaxInterval)
+			delay = max(delay, RetryAfter(err))
+			if opts.OnError != nil {
+				opts.OnError(err, delay)
+			}
+		} else {
+			if current != nil && current.Item == nil {
+				current = nil
+			}
+			onUpdate(current)
+
+			if current != nil && current.Playing {
+				delay = opts.Interval
+			} else {
+				delay = backoff(delay, opts.MaxInterval)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// pollPlayer fetches the player state, or just the current track if full is false
+func pollPlayer(ctx context.Context, client *spotify.Client, full bool) (*spotify.PlayerState, error) {
+	if full {
+		return client.PlayerState(ctx)
+	}
+
+	current, err := client.PlayerCurrentlyPlaying(ctx)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	return &spotify.PlayerState{CurrentlyPlaying: *current}, nil
+}
+
+// backoff doubles the delay without exceeding max
+func backoff(delay, max time.Duration) time.Duration {
+	delay *= 2
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,278 @@
+// Package stats aggregates top items and listening history into a taste profile.
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/history"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Count is a labelled tally used for genre and decade breakdowns
+type Count struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+}
+
+// FeatureProfile is the average audio-feature profile of a set of tracks
+type FeatureProfile struct {
+	Tracks           int     `json:"tracks"`
+	Energy           float64 `json:"energy"`
+	Valence          float64 `json:"valence"`
+	Danceability     float64 `json:"danceability"`
+	Acousticness     float64 `json:"acousticness"`
+	Instrumentalness float64 `json:"instrumentalness"`
+	Speechiness      float64 `json:"speechiness"`
+	Tempo            float64 `json:"tempo"`
+	Loudness         float64 `json:"loudness"`
+}
+
+// Trend describes how an artist's rank moved between two time ranges.
+// A rank of 0 means the artist wasn't in that range's top list.
+type Trend struct {
+	Artist   string `json:"artist"`
+	FromRank int    `json:"from_rank"`
+	ToRank   int    `json:"to_rank"`
+	Change   int    `json:"change"` // positive when climbing
+}
+
+// Discovery measures how many recent artists are new to the listener
+type Discovery struct {
+	Source     string  `json:"source"` // "top_artists" or "history"
+	Artists    int     `json:"artists"`
+	NewArtists int     `json:"new_artists"`
+	Rate       float64 `json:"rate"`
+}
+
+// HistorySummary summarises locally recorded plays
+type HistorySummary struct {
+	Plays       int     `json:"plays"`
+	ListenedMs  int64   `json:"listened_ms"`
+	Artists     int     `json:"artists"`
+	TopArtists  []Count `json:"top_artists"`
+	FirstPlayAt string  `json:"first_play_at"`
+}
+
+// TopGenres aggregates artist genres across ranked lists, weighting each
+// artist by its position so favourites count more than the tail
+func TopGenres(lists [][]spotify.FullArtist, n int) []Count {
+	weights := map[string]float64{}
+	for _, artists := range lists {
+		for rank, artist := range artists {
+			weight := float64(len(artists)-rank) / float64(len(artists))
+			for _, genre := range artist.Genres {
+				weights[strings.ToLower(genre)] += weight
+			}
+		}
+	}
+	return topCounts(weights, n)
+}
+
+// AverageFeatures averages the audio features of the given tracks
+func AverageFeatures(features map[spotify.ID]*spotify.AudioFeatures) *FeatureProfile {
+	if len(features) == 0 {
+		return nil
+	}
+
+	p := &FeatureProfile{Tracks: len(features)}
+	for _, f := range features {
+		p.Energy += float64(f.Energy)
+		p.Valence += float64(f.Valence)
+		p.Danceability += float64(f.Danceability)
+		p.Acousticness += float64(f.Acousticness)
+		p.Instrumentalness += float64(f.Instrumentalness)
+		p.Speechiness += float64(f.Speechiness)
+		p.Tempo += float64(f.Tempo)
+		p.Loudness += float64(f.Loudness)
+	}
+
+	n := float64(len(features))
+	p.Energy /= n
+	p.Valence /= n
+	p.Danceability /= n
+	p.Acousticness /= n
+	p.Instrumentalness /= n
+	p.Speechiness /= n
+	p.Tempo /= n
+	p.Loudness /= n
+	return p
+}
+
+// Decades counts tracks by release decade, oldest first
+func Decades(tracks []spotify.FullTrack) []Count {
+	counts := map[int]float64{}
+	for _, track := range tracks {
+		year := spotifyx.ParseYear(track.Album.ReleaseDate)
+		if year == 0 {
+			continue
+		}
+		counts[year/10*10]++
+	}
+
+	decades := make([]int, 0, len(counts))
+	for decade := range counts {
+		decades = append(decades, decade)
+	}
+	sort.Ints(decades)
+
+	out := make([]Count, 0, len(decades))
+	for _, decade := range decades {
+		out = append(out, Count{Label: decadeLabel(decade), Value: counts[decade]})
+	}
+	return out
+}
+
+// Trends compares artist ranks between an older and a newer top list and
+// returns the biggest climbers (including new entries) and fallers
+func Trends(older, newer []spotify.FullArtist, n int) (up, down []Trend) {
+	oldRank := rankByID(older)
+	newRank := rankByID(newer)
+
+	for i, artist := range newer {
+		from := oldRank[artist.ID]
+		change := len(older) + 1 - (i + 1) // new entries climb from below the list
+		if from > 0 {
+			change = from - (i + 1)
+		}
+		if change > 0 {
+			up = append(up, Trend{Artist: artist.Name, FromRank: from, ToRank: i + 1, Change: change})
+		}
+	}
+
+	for i, artist := range older {
+		to := newRank[artist.ID]
+		change := -(len(newer) + 1 - (i + 1)) // dropped out entirely
+		if to > 0 {
+			change = (i + 1) - to
+		}
+		if change < 0 {
+			down = append(down, Trend{Artist: artist.Name, FromRank: i + 1, ToRank: to, Change: change})
+		}
+	}
+
+	sort.SliceStable(up, func(i, j int) bool { return up[i].Change > up[j].Change })
+	sort.SliceStable(down, func(i, j int) bool { return down[i].Change < down[j].Change })
+	return limitTrends(up, n), limitTrends(down, n)
+}
+
+// TopArtistDiscovery is the share of short-term top artists that aren't in
+// the long-term top list
+func TopArtistDiscovery(short, long []spotify.FullArtist) Discovery {
+	known := rankByID(long)
+	d := Discovery{Source: "top_artists", Artists: len(short)}
+	for _, artist := range short {
+		if known[artist.ID] == 0 {
+			d.NewArtists++
+		}
+	}
+	if d.Artists > 0 {
+		d.Rate = float64(d.NewArtists) / float64(d.Artists)
+	}
+	return d
+}
+
+// HistoryDiscovery is the share of artists played within window (counting
+// back from now) that never appeared in earlier history
+func HistoryDiscovery(plays []history.Play, now time.Time, window time.Duration) Discovery {
+	cutoff := now.Add(-window)
+	before := map[string]bool{}
+	recent := map[string]bool{}
+	for _, play := range plays {
+		artist := play.Artist()
+		if play.EndedAt.Before(cutoff) {
+			before[artist] = true
+		} else {
+			recent[artist] = true
+		}
+	}
+
+	d := Discovery{Source: "history", Artists: len(recent)}
+	for artist := range recent {
+		if !before[artist] {
+			d.NewArtists++
+		}
+	}
+	if d.Artists > 0 {
+		d.Rate = float64(d.NewArtists) / float64(d.Artists)
+	}
+	return d
+}
+
+// SummarizeHistory totals plays, listening time and the most played artists
+func SummarizeHistory(plays []history.Play, n int) *HistorySummary {
+	if len(plays) == 0 {
+		return nil
+	}
+
+	summary := &HistorySummary{
+		Plays:       len(plays),
+		FirstPlayAt: plays[0].StartedAt.Format(time.RFC3339),
+	}
+	counts := map[string]float64{}
+	for _, play := range plays {
+		summary.ListenedMs += int64(play.ListenedMs)
+		counts[play.Artist()]++
+	}
+	summary.Artists = len(counts)
+	summary.TopArtists = topCounts(counts, n)
+	return summary
+}
+
+func rankByID(artists []spotify.FullArtist) map[spotify.ID]int {
+	ranks := make(map[spotify.ID]int, len(artists))
+	for i, artist := range artists {
+		ranks[artist.ID] = i + 1
+	}
+	return ranks
+}
+
+func limitTrends(trends []Trend, n int) []Trend {
+	if n > 0 && len(trends) > n {
+		return trends[:n]
+	}
+	return trends
+}
+
+// topCounts sorts tallies by value (then label) and keeps the top n
+func topCounts(tallies map[string]float64, n int) []Count {
+	out := make([]Count, 0, len(tallies))
+	for label, value := range tallies {
+		out = append(out, Count{Label: label, Value: value})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Value != out[j].Value {
+			return out[i].Value > out[j].Value
+		}
+		return out[i].Label < out[j].Label
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// decadeLabel names a decade the way discover's --decade flag does: 90s, 2010s
+func decadeLabel(decade int) string {
+	if decade >= 1900 && decade < 2000 {
+		return fmt.Sprintf("%02ds", decade%100)
+	}
+	return fmt.Sprintf("%ds", decade)
+}
+
+// Report is the full statistics report produced by `moodify stats`
+type Report struct {
+	GeneratedAt  time.Time           `json:"generated_at"`
+	TopArtists   map[string][]string `json:"top_artists"` // by time range
+	TopTracks    map[string][]string `json:"top_tracks"`  // by time range, "Track — Artist"
+	TopGenres    []Count             `json:"top_genres"`
+	Features     *FeatureProfile     `json:"audio_features,omitempty"`
+	Decades      []Count             `json:"decades"`
+	Discovery    []Discovery         `json:"discovery"`
+	TrendingUp   []Trend             `json:"trending_up"`
+	TrendingDown []Trend             `json:"trending_down"`
+	History      *HistorySummary     `json:"history,omitempty"`
+}
@@ -0,0 +1,72 @@
+//go:build !windows
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// makeRaw puts the terminal into raw mode with stty, returning a function
+// that restores the previous settings
+func makeRaw(f *os.File) (func() error, error) {
+	saved, err := stty(f, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(f, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := stty(f, strings.TrimSpace(saved))
+		return err
+	}, nil
+}
+
+// size asks stty for the terminal's dimensions
+func size(f *os.File) (width, height int, err error) {
+	out, err := stty(f, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscan(out, &height, &width); err != nil {
+		return 0, 0, fmt.Errorf("unexpected stty size output %q", out)
+	}
+	return width, height, nil
+}
+
+// watchResize calls resized whenever the terminal gets SIGWINCH, until the
+// returned function is called
+func watchResize(resized func()) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				resized()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s failed: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("raw terminal mode isn't supported on Windows yet")
+
+func makeRaw(f *os.File) (func() error, error) {
+	return nil, errUnsupported
+}
+
+func size(f *os.File) (width, height int, err error) {
+	return 0, 0, errUnsupported
+}
+
+func watchResize(resized func()) (stop func()) {
+	return func() {}
+}
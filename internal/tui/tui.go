@@ -0,0 +1,211 @@
+// Package tui provides the raw terminal handling moodify's interactive views
+// need: raw mode, key decoding and full-screen redraws.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrUnavailable is returned by Open when stdin or stdout isn't an
+// interactive terminal, or the platform isn't supported
+var ErrUnavailable = errors.New("interactive mode needs a terminal")
+
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	clearScreen  = "\x1b[H\x1b[2J"
+)
+
+// Key identifies a key press
+type Key int
+
+// Keys the interactive views understand
+const (
+	KeyUnknown Key = iota
+	KeyRune
+	KeyUp
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyDelete
+	KeyCtrlC
+)
+
+// Event is a single key press; Rune is set for KeyRune
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// Terminal is a terminal in raw mode showing a full-screen view
+type Terminal struct {
+	in         *bufio.Reader
+	out        *os.File
+	restore    func() error
+	stopResize func()
+
+	// The size is asked for once and again only after the terminal is resized
+	mu            sync.Mutex
+	width, height int
+}
+
+// Open switches the terminal to raw mode and an alternate screen. Close must
+// be called to restore it.
+func Open() (*Terminal, error) {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return nil, ErrUnavailable
+	}
+
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	fmt.Print(altScreenOn + hideCursor)
+	t := &Terminal{in: bufio.NewReader(os.Stdin), out: os.Stdout, restore: restore}
+	t.stopResize = watchResize(func() {
+		t.mu.Lock()
+		t.width, t.height = 0, 0
+		t.mu.Unlock()
+	})
+	return t, nil
+}
+
+// Close leaves the alternate screen and restores the terminal's mode
+func (t *Terminal) Close() error {
+	t.stopResize()
+	fmt.Fprint(t.out, showCursor+altScreenOff)
+	return t.restore()
+}
+
+// Size returns the terminal's width and height, or 80x24 if unknown
+func (t *Terminal) Size() (width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.width == 0 {
+		width, height, err := size(os.Stdin)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		t.width, t.height = width, height
+	}
+	return t.width, t.height
+}
+
+// Draw clears the screen and writes lines, cutting off anything that
+// doesn't fit
+func (t *Terminal) Draw(lines []string) {
+	_, height := t.Size()
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	// Raw mode doesn't translate newlines, so return the carriage explicitly
+	b.WriteString(strings.Join(lines, "\r\n"))
+	fmt.Fprint(t.out, b.String())
+}
+
+// ReadEvent blocks until a key is pressed
+func (t *Terminal) ReadEvent() (Event, error) {
+	r, _, err := t.in.ReadRune()
+	if err != nil {
+		return Event{}, err
+	}
+
+	switch r {
+	case '\r', '\n':
+		return Event{Key: KeyEnter}, nil
+	case 127, 8:
+		return Event{Key: KeyBackspace}, nil
+	case 3:
+		return Event{Key: KeyCtrlC}, nil
+	case 0x1b:
+		return t.readEscape()
+	case utf8.RuneError:
+		return Event{Key: KeyUnknown}, nil
+	}
+	return Event{Key: KeyRune, Rune: r}, nil
+}
+
+// readEscape decodes the arrow and paging key sequences terminals send. A
+// lone escape (nothing else buffered) is the Escape key itself.
+func (t *Terminal) readEscape() (Event, error) {
+	if t.in.Buffered() == 0 {
+		return Event{Key: KeyEscape}, nil
+	}
+
+	prefix, err := t.in.ReadByte()
+	if err != nil {
+		return Event{}, err
+	}
+	if prefix != '[' && prefix != 'O' {
+		return Event{Key: KeyUnknown}, nil
+	}
+
+	code, err := t.in.ReadByte()
+	if err != nil {
+		return Event{}, err
+	}
+	switch code {
+	case 'A':
+		return Event{Key: KeyUp}, nil
+	case 'B':
+		return Event{Key: KeyDown}, nil
+	case 'H':
+		return Event{Key: KeyHome}, nil
+	case 'F':
+		return Event{Key: KeyEnd}, nil
+	}
+
+	// Sequences like ESC [ 5 ~ carry a number before the tilde
+	if code >= '0' && code <= '9' {
+		number := string(code)
+		for {
+			b, err := t.in.ReadByte()
+			if err != nil {
+				return Event{}, err
+			}
+			if b == '~' {
+				break
+			}
+			number += string(b)
+		}
+		switch number {
+		case "1", "7":
+			return Event{Key: KeyHome}, nil
+		case "4", "8":
+			return Event{Key: KeyEnd}, nil
+		case "3":
+			return Event{Key: KeyDelete}, nil
+		case "5":
+			return Event{Key: KeyPageUp}, nil
+		case "6":
+			return Event{Key: KeyPageDown}, nil
+		}
+	}
+	return Event{Key: KeyUnknown}, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
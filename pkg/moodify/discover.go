@@ -0,0 +1,357 @@
+package moodify
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Discovery modes: explicit criteria, or with none, the user's taste or
+// random popular genres when that's unavailable
+const (
+	DiscoverCriteria = "criteria"
+	DiscoverPersonal = "personal"
+	DiscoverGenres   = "genres"
+)
+
+// Criteria narrow down a discovery. Moods are presets whose audio ranges
+// apply, such as the default happy, sad, energetic, chill, angry or romantic;
+// a preset also brings its genres and seeds. Energy is low, medium or high;
+// popularity is mainstream, underground or balanced; decades look like 80s or
+// 2010s.
+type Criteria struct {
+	Genre      string `json:"genre,omitempty"`
+	Decade     string `json:"decade,omitempty"`
+	Mood       string `json:"mood,omitempty"`
+	Energy     string `json:"energy,omitempty"`
+	Popularity string `json:"popularity,omitempty"`
+	Preset     string `json:"preset,omitempty"`
+}
+
+// Empty reports whether no criteria were given
+func (c Criteria) Empty() bool {
+	return c == Criteria{}
+}
+
+// DiscoverOptions tune Discover
+type DiscoverOptions struct {
+	Criteria Criteria // with none, discover from the user's taste
+	Limit    int      // tracks to return, 1-50 (default 20); ignored when fitting
+	Fit      Fit      // fill a duration instead of returning Limit tracks
+}
+
+// DiscoverResult is what a discovery found and how
+type DiscoverResult struct {
+	Mode      string   `json:"mode"` // DiscoverCriteria, DiscoverPersonal or DiscoverGenres
+	Criteria  Criteria `json:"criteria"`
+	Genres    []string `json:"genres,omitempty"` // seed genres in DiscoverGenres mode
+	Tracks    []Track  `json:"tracks"`
+	RuntimeMs int64    `json:"runtime_ms"`
+
+	// Items are the Spotify tracks behind Tracks, for playback and playlists
+	Items []spotify.SimpleTrack `json:"-"`
+	// Seeds are the recommendation seeds used
+	Seeds spotify.Seeds `json:"-"`
+}
+
+// Title describes the discoveries in a few words
+func (d *DiscoverResult) Title() string {
+	switch d.Mode {
+	case DiscoverPersonal:
+		return "Personalized discoveries"
+	case DiscoverGenres:
+		return fmt.Sprintf("Discoveries from %s", strings.Join(d.Genres, ", "))
+	}
+	return "Discoveries"
+}
+
+// Discover finds tracks matching the criteria, or picked from the user's
+// taste when there are none
+func (s *Service) Discover(ctx context.Context, opts DiscoverOptions) (*DiscoverResult, error) {
+	// Validate limit
+	limit := opts.Limit
+	if limit > 50 {
+		limit = 50
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	// If no specific criteria provided, do random discovery
+	var found *DiscoverResult
+	var err error
+	if opts.Criteria.Empty() {
+		found, err = s.randomDiscovery(ctx, opts.Fit.candidates(limit))
+	} else {
+		found, err = s.criteriaDiscovery(ctx, opts.Criteria, opts.Fit.candidates(limit))
+	}
+	if err != nil {
+		return nil, errs.Classify(err)
+	}
+
+	// Pick tracks to fill the target length if one was given
+	if found.Items, err = FitTracks(found.Items, opts.Fit); err != nil {
+		return nil, errs.Classify(err)
+	}
+	found.Tracks = NewTracks(found.Items)
+	found.RuntimeMs = Runtime(found.Items).Milliseconds()
+	return found, nil
+}
+
+// criteriaDiscovery gets recommendations matching the criteria
+func (s *Service) criteriaDiscovery(ctx context.Context, criteria Criteria, limit int) (*DiscoverResult, error) {
+	// Look up the mood and preset
+	var mood, base *Preset
+	var err error
+	if criteria.Mood != "" {
+		if mood, err = LoadPreset(criteria.Mood); err != nil {
+			return nil, err
+		}
+	}
+	if criteria.Preset != "" {
+		if base, err = LoadPreset(criteria.Preset); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build recommendation parameters
+	seeds, trackAttribs, yearStart, yearEnd := discoveryParameters(criteria, mood, base)
+	if len(seeds.Artists)+len(seeds.Tracks)+len(seeds.Genres) == 0 {
+		// A mood alone has no seeds, so fall back to the user's taste
+		seeds = s.Seeds(ctx, Filters{})
+	}
+
+	// Get recommendations
+	recs, err := s.client.GetRecommendations(ctx, seeds, trackAttribs,
+		spotify.Limit(limit), spotify.Market(s.market))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+	}
+
+	tracks := recs.Tracks
+
+	// Filter by year if decade specified
+	if yearStart > 0 || yearEnd > 0 {
+		filtered := make([]spotify.SimpleTrack, 0, len(tracks))
+		for _, track := range tracks {
+			year := spotifyx.ParseYear(track.Album.ReleaseDate)
+			if (yearStart == 0 || year >= yearStart) && (yearEnd == 0 || year <= yearEnd) {
+				filtered = append(filtered, track)
+			}
+		}
+		tracks = filtered
+	}
+
+	return &DiscoverResult{Mode: DiscoverCriteria, Criteria: criteria, Items: tracks, Seeds: seeds}, nil
+}
+
+// randomDiscovery gets recommendations seeded by the user's top artists
+func (s *Service) randomDiscovery(ctx context.Context, limit int) (*DiscoverResult, error) {
+	// Get user's top genres from their top artists
+	topArtists, err := s.client.CurrentUsersTopArtists(ctx, spotify.Limit(5))
+	if err != nil {
+		// Fallback to popular genres if we can't get user's top artists
+		return s.genreBasedDiscovery(ctx, limit)
+	}
+
+	if len(topArtists.Artists) == 0 {
+		return s.genreBasedDiscovery(ctx, limit)
+	}
+
+	// Use user's top artists as seeds
+	seeds := spotify.Seeds{}
+	for i, artist := range topArtists.Artists {
+		if i >= 3 { // Limit to 3 artist seeds
+			break
+		}
+		seeds.Artists = append(seeds.Artists, artist.ID)
+	}
+
+	// Add some randomness to attributes
+	attrs := spotify.NewTrackAttributes().
+		MinPopularity(20).
+		MaxPopularity(80)
+
+	// Randomly adjust some attributes for discovery
+	if rand.Float32() > 0.5 {
+		attrs = attrs.MinEnergy(0.4).MaxEnergy(1.0)
+	}
+	if rand.Float32() > 0.5 {
+		attrs = attrs.MinValence(0.3).MaxValence(0.9)
+	}
+
+	recs, err := s.client.GetRecommendations(ctx, seeds, attrs,
+		spotify.Limit(limit), spotify.Market(s.market))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personalized recommendations: %w", err)
+	}
+
+	return &DiscoverResult{Mode: DiscoverPersonal, Items: recs.Tracks, Seeds: seeds}, nil
+}
+
+// genreBasedDiscovery gets recommendations from a few random popular genres
+func (s *Service) genreBasedDiscovery(ctx context.Context, limit int) (*DiscoverResult, error) {
+	// Fallback: use popular genres
+	popularGenres := []string{"pop", "rock", "indie", "electronic", "hip-hop", "jazz", "classical"}
+
+	selectedGenres := make([]string, 0, 3)
+	for i := 0; i < 3 && i < len(popularGenres); i++ {
+		idx := rand.Intn(len(popularGenres))
+		selectedGenres = append(selectedGenres, popularGenres[idx])
+	}
+
+	seeds := spotify.Seeds{Genres: selectedGenres}
+	attrs := spotify.NewTrackAttributes().MinPopularity(20).MaxPopularity(80)
+
+	recs, err := s.client.GetRecommendations(ctx, seeds, attrs,
+		spotify.Limit(limit), spotify.Market(s.market))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get genre-based recommendations: %w", err)
+	}
+
+	return &DiscoverResult{Mode: DiscoverGenres, Genres: selectedGenres, Items: recs.Tracks, Seeds: seeds}, nil
+}
+
+// discoveryParameters turns criteria, with the mood and preset they name,
+// into recommendation seeds, attributes and a release year range
+func discoveryParameters(criteria Criteria, mood, base *Preset) (spotify.Seeds, *spotify.TrackAttributes, int, int) {
+	seeds := spotify.Seeds{}
+	attrs := spotify.NewTrackAttributes()
+	var yearStart, yearEnd int
+
+	// Handle genre
+	if criteria.Genre != "" {
+		seeds.Genres = append(seeds.Genres, criteria.Genre)
+	}
+
+	// Handle decade
+	if criteria.Decade != "" {
+		switch criteria.Decade {
+		case "60s", "1960s":
+			yearStart, yearEnd = 1960, 1969
+		case "70s", "1970s":
+			yearStart, yearEnd = 1970, 1979
+		case "80s", "1980s":
+			yearStart, yearEnd = 1980, 1989
+		case "90s", "1990s":
+			yearStart, yearEnd = 1990, 1999
+		case "2000s":
+			yearStart, yearEnd = 2000, 2009
+		case "2010s":
+			yearStart, yearEnd = 2010, 2019
+		case "2020s":
+			yearStart, yearEnd = 2020, 2029
+		}
+	}
+
+	// Default popularity range, unless asked for (a preset may narrow it)
+	switch criteria.Popularity {
+	case "mainstream", "popular", "underground", "obscure", "balanced":
+	default:
+		attrs = attrs.MinPopularity(10).MaxPopularity(90)
+	}
+
+	// Handle preset: its seeds, genres, ranges and era
+	if base != nil {
+		seeds = addSeeds(seeds, base.Seeds.Spotify())
+		seeds = addSeeds(seeds, spotify.Seeds{Genres: ValidGenres(base.Filters.Genres)})
+		attrs = filterAttributes(attrs, base.Filters)
+		if criteria.Decade == "" {
+			yearStart, yearEnd = base.Filters.YearStart, base.Filters.YearEnd
+		}
+	}
+
+	// Handle mood: only its audio ranges apply
+	if mood != nil {
+		attrs = filterAttributes(attrs, mood.Filters)
+	}
+
+	// Handle energy
+	switch criteria.Energy {
+	case "low":
+		attrs = attrs.MaxEnergy(0.4)
+	case "medium":
+		attrs = attrs.MinEnergy(0.4).MaxEnergy(0.7)
+	case "high":
+		attrs = attrs.MinEnergy(0.7)
+	}
+
+	// Handle popularity
+	switch criteria.Popularity {
+	case "mainstream", "popular":
+		attrs = attrs.MinPopularity(70)
+	case "underground", "obscure":
+		attrs = attrs.MaxPopularity(30)
+	case "balanced":
+		attrs = attrs.MinPopularity(20).MaxPopularity(80)
+	}
+
+	return seeds, attrs, yearStart, yearEnd
+}
+
+// maxSeeds is the most seeds Spotify accepts in one recommendations request
+const maxSeeds = 5
+
+// addSeeds appends extra seeds while there is room for them
+func addSeeds(seeds, extra spotify.Seeds) spotify.Seeds {
+	room := func() bool {
+		return len(seeds.Artists)+len(seeds.Tracks)+len(seeds.Genres) < maxSeeds
+	}
+	for _, id := range extra.Artists {
+		if room() {
+			seeds.Artists = append(seeds.Artists, id)
+		}
+	}
+	for _, id := range extra.Tracks {
+		if room() {
+			seeds.Tracks = append(seeds.Tracks, id)
+		}
+	}
+	for _, genre := range extra.Genres {
+		if room() && !slices.Contains(seeds.Genres, genre) {
+			seeds.Genres = append(seeds.Genres, genre)
+		}
+	}
+	return seeds
+}
+
+// filterAttributes sets the audio and popularity ranges the filters give
+func filterAttributes(attrs *spotify.TrackAttributes, f Filters) *spotify.TrackAttributes {
+	if f.MinDanceability > 0 {
+		attrs = attrs.MinDanceability(f.MinDanceability)
+	}
+	if f.MaxDanceability > 0 {
+		attrs = attrs.MaxDanceability(f.MaxDanceability)
+	}
+	if f.MinEnergy > 0 {
+		attrs = attrs.MinEnergy(f.MinEnergy)
+	}
+	if f.MaxEnergy > 0 {
+		attrs = attrs.MaxEnergy(f.MaxEnergy)
+	}
+	if f.MinValence > 0 {
+		attrs = attrs.MinValence(f.MinValence)
+	}
+	if f.MaxValence > 0 {
+		attrs = attrs.MaxValence(f.MaxValence)
+	}
+	if f.MinTempo > 0 {
+		attrs = attrs.MinTempo(f.MinTempo)
+	}
+	if f.MaxTempo > 0 {
+		attrs = attrs.MaxTempo(f.MaxTempo)
+	}
+	if f.MinPopularity > 0 {
+		attrs = attrs.MinPopularity(f.MinPopularity)
+	}
+	if f.MaxPopularity > 0 {
+		attrs = attrs.MaxPopularity(f.MaxPopularity)
+	}
+	return attrs
+}
@@ -0,0 +1,21 @@
+package moodify
+
+import "github.com/lorrehuggan/moodify/internal/errs"
+
+// Kinds of failure returned by the Service, for use with errors.Is. Spotify
+// API errors are classified by status, so errors.As still finds the
+// spotify.Error behind them.
+var (
+	ErrNotAuthenticated = errs.ErrNotAuthenticated // no saved login, or it was revoked
+	ErrMissingScope     = errs.ErrMissingScope     // the login lacks a permission; log in again
+	ErrRateLimited      = errs.ErrRateLimited      // Spotify is throttling requests
+	ErrNoActiveDevice   = errs.ErrNoActiveDevice   // nothing to control playback on
+	ErrPremiumRequired  = errs.ErrPremiumRequired  // playback control needs Premium
+	ErrNotFound         = errs.ErrNotFound         // no such track, playlist or user
+	ErrAIUnavailable    = errs.ErrAIUnavailable    // OpenAI parsing failed (see ParseResult.AIError)
+)
+
+// Classify marks Spotify API errors in err with one of the Err* kinds
+func Classify(err error) error {
+	return errs.Classify(err)
+}
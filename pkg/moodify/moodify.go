@@ -0,0 +1,72 @@
+// Package moodify is the library behind the moodify CLI: natural language
+// search, discovery, playlists and playback on top of the Spotify Web API.
+//
+// A Service wraps an authenticated Spotify client:
+//
+//	svc, err := moodify.Connect(ctx) // uses the token saved by `moodify login`
+//	if err != nil {
+//		return err
+//	}
+//	result, err := svc.Search(ctx, "chill 90s indie", moodify.SearchOptions{Limit: 20})
+//
+// Results carry JSON tags matching the CLI's --output json and `moodify serve`.
+package moodify
+
+import (
+	"context"
+
+	"github.com/lorrehuggan/moodify/internal/auth"
+	"github.com/zmb3/spotify/v2"
+)
+
+// DefaultMarket is the market used for recommendations unless WithMarket is given
+const DefaultMarket = "US"
+
+// Service runs moodify features against a Spotify account. It is safe for
+// concurrent use as long as the underlying client is.
+type Service struct {
+	client *spotify.Client
+	market string
+}
+
+// Option configures a Service
+type Option func(*Service)
+
+// WithMarket sets the ISO market code used for recommendations and search
+func WithMarket(market string) Option {
+	return func(s *Service) {
+		if market != "" {
+			s.market = market
+		}
+	}
+}
+
+// New returns a Service using an authenticated Spotify client
+func New(client *spotify.Client, opts ...Option) *Service {
+	s := &Service{client: client, market: DefaultMarket}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Connect returns a Service authenticated with the token saved by
+// `moodify login`, refreshing it if needed
+func Connect(ctx context.Context, opts ...Option) (*Service, error) {
+	config := auth.ConfigWithClientID(auth.GetClientIDFromEnv())
+	client, err := auth.GetAuthenticatedClient(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return New(client, opts...), nil
+}
+
+// Client returns the underlying Spotify client, for calls the Service doesn't cover
+func (s *Service) Client() *spotify.Client {
+	return s.client
+}
+
+// Market returns the market used for recommendations and search
+func (s *Service) Market() string {
+	return s.market
+}
@@ -0,0 +1,220 @@
+package moodify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/analysis"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	"github.com/zmb3/spotify/v2"
+)
+
+// NowPlaying holds everything known about the current track. The CLI passes
+// it to `moodify now --format` templates.
+type NowPlaying struct {
+	Track   string   `json:"track"`   // Track name
+	Artist  string   `json:"artist"`  // Primary artist
+	Artists []string `json:"artists"` // All credited artists
+	Album   string   `json:"album"`   // Album name
+	Year    string   `json:"year"`    // Album release year, e.g. "1997"
+	URL     string   `json:"url"`     // Spotify web link
+
+	Playing    bool    `json:"playing"`     // Whether playback is running
+	Status     string  `json:"status"`      // "playing" or "paused"
+	Progress   string  `json:"progress"`    // Position as m:ss
+	Duration   string  `json:"duration"`    // Length as m:ss
+	ProgressMs int     `json:"progress_ms"` // Position in milliseconds
+	DurationMs int     `json:"duration_ms"` // Length in milliseconds
+	Percent    float64 `json:"percent"`     // Position as 0-100
+
+	Device     string `json:"device"`      // Active device name (empty if unknown)
+	DeviceType string `json:"device_type"` // e.g. "Computer", "Smartphone"
+	Shuffle    bool   `json:"shuffle"`     // Shuffle state
+	Repeat     string `json:"repeat"`      // "off", "track" or "context"
+	Volume     int    `json:"volume"`      // Device volume percent
+
+	// Features is only filled in when requested
+	Features *NowPlayingFeatures `json:"features,omitempty"`
+}
+
+// NowPlayingFeatures are the audio features of the current track
+type NowPlayingFeatures struct {
+	Key              string  `json:"key"`  // Note name, e.g. "F#/Gb"
+	Mode             string  `json:"mode"` // "major" or "minor"
+	Tempo            float64 `json:"tempo"`
+	Energy           float64 `json:"energy"`
+	Danceability     float64 `json:"danceability"`
+	Valence          float64 `json:"valence"`
+	Loudness         float64 `json:"loudness"`
+	Speechiness      float64 `json:"speechiness"`
+	Acousticness     float64 `json:"acousticness"`
+	Instrumentalness float64 `json:"instrumentalness"`
+}
+
+// NowPlaying collects the current track, player state and optionally audio
+// features. It returns nil when nothing is playing.
+func (s *Service) NowPlaying(ctx context.Context, withFeatures bool) (*NowPlaying, error) {
+	currently, err := s.client.PlayerCurrentlyPlaying(ctx)
+	if err != nil {
+		return nil, errs.Classify(err)
+	}
+
+	if currently == nil || currently.Item == nil {
+		return nil, nil
+	}
+
+	// Device info is optional, so ignore failures here
+	playerState, err := s.client.PlayerState(ctx)
+	if err != nil {
+		playerState = nil
+	}
+
+	np := NewNowPlaying(currently, playerState)
+
+	if withFeatures {
+		// Features are optional too
+		np.Features, _ = s.TrackFeatures(ctx, currently.Item.ID)
+	}
+
+	return np, nil
+}
+
+// TrackFeatures returns a track's audio features in NowPlaying form, or nil
+// when Spotify has none for it
+func (s *Service) TrackFeatures(ctx context.Context, id spotify.ID) (*NowPlayingFeatures, error) {
+	features, err := s.client.GetAudioFeatures(ctx, id)
+	if err != nil {
+		return nil, errs.Classify(err)
+	}
+	if len(features) == 0 || features[0] == nil {
+		return nil, nil
+	}
+	return newNowPlayingFeatures(features[0]), nil
+}
+
+// NewNowPlaying builds a NowPlaying from a player snapshot; state may be nil
+func NewNowPlaying(currently *spotify.CurrentlyPlaying, state *spotify.PlayerState) *NowPlaying {
+	track := currently.Item
+	np := &NowPlaying{
+		Track:      track.Name,
+		Album:      track.Album.Name,
+		URL:        track.ExternalURLs["spotify"],
+		Playing:    currently.Playing,
+		ProgressMs: int(currently.Progress),
+		DurationMs: int(track.Duration),
+		Repeat:     "off",
+	}
+
+	for _, artist := range track.Artists {
+		np.Artists = append(np.Artists, artist.Name)
+	}
+	if len(np.Artists) > 0 {
+		np.Artist = np.Artists[0]
+	}
+
+	if len(track.Album.ReleaseDate) >= 4 {
+		np.Year = track.Album.ReleaseDate[:4]
+	}
+
+	if state != nil {
+		np.Device = state.Device.Name
+		np.DeviceType = state.Device.Type
+		np.Shuffle = state.ShuffleState
+		np.Volume = int(state.Device.Volume)
+		if state.RepeatState != "" {
+			np.Repeat = state.RepeatState
+		}
+	}
+
+	np.updateProgress()
+	return np
+}
+
+// newNowPlayingFeatures converts Spotify audio features
+func newNowPlayingFeatures(feature *spotify.AudioFeatures) *NowPlayingFeatures {
+	mode := "minor"
+	if feature.Mode == 1 {
+		mode = "major"
+	}
+	return &NowPlayingFeatures{
+		Key:              analysis.KeyName(int(feature.Key)),
+		Mode:             mode,
+		Tempo:            float64(feature.Tempo),
+		Energy:           float64(feature.Energy),
+		Danceability:     float64(feature.Danceability),
+		Valence:          float64(feature.Valence),
+		Loudness:         float64(feature.Loudness),
+		Speechiness:      float64(feature.Speechiness),
+		Acousticness:     float64(feature.Acousticness),
+		Instrumentalness: float64(feature.Instrumentalness),
+	}
+}
+
+// Advance moves the position on by d if the track is playing, as when a
+// snapshot is served some time after it was taken
+func (np *NowPlaying) Advance(d time.Duration) {
+	if !np.Playing {
+		return
+	}
+	np.ProgressMs += int(d.Milliseconds())
+	np.updateProgress()
+}
+
+// updateProgress recomputes the derived progress fields from ProgressMs
+func (np *NowPlaying) updateProgress() {
+	if np.DurationMs > 0 && np.ProgressMs > np.DurationMs {
+		np.ProgressMs = np.DurationMs
+	}
+
+	np.Status = "paused"
+	if np.Playing {
+		np.Status = "playing"
+	}
+
+	np.Progress = formatClock(time.Duration(np.ProgressMs) * time.Millisecond)
+	np.Duration = formatClock(time.Duration(np.DurationMs) * time.Millisecond)
+	np.Percent = 0
+	if np.DurationMs > 0 {
+		np.Percent = float64(np.ProgressMs) / float64(np.DurationMs) * 100
+	}
+}
+
+// formatClock renders a position or length as m:ss
+func formatClock(d time.Duration) string {
+	minutes := int(d.Minutes())
+	seconds := int(d.Seconds()) % 60
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
+// Play replaces the current playback with the given tracks
+func (s *Service) Play(ctx context.Context, tracks []spotify.SimpleTrack) error {
+	uris := make([]spotify.URI, 0, len(tracks))
+	for _, track := range tracks {
+		if track.URI != "" {
+			uris = append(uris, track.URI)
+		}
+	}
+
+	if len(uris) == 0 {
+		return fmt.Errorf("no playable tracks found")
+	}
+
+	return errs.ClassifyPlayback(s.client.PlayOpt(ctx, &spotify.PlayOptions{URIs: uris}))
+}
+
+// Queue appends tracks to the user's playback queue, one request per track as
+// the Spotify API does not support batch queueing. It returns how many were queued.
+func (s *Service) Queue(ctx context.Context, tracks []spotify.SimpleTrack) (int, error) {
+	queued := 0
+	for _, track := range tracks {
+		if track.ID == "" {
+			continue
+		}
+		if err := s.client.QueueSong(ctx, track.ID); err != nil {
+			return queued, errs.ClassifyPlayback(err)
+		}
+		queued++
+	}
+	return queued, nil
+}
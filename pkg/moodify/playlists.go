@@ -0,0 +1,131 @@
+package moodify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/errs"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// SavePlaylistOptions tune SavePlaylist
+type SavePlaylistOptions struct {
+	Public      bool   // make the playlist public (default private)
+	Description string // defaults to a note that moodify made it
+}
+
+// SavePlaylist creates a new playlist with the given tracks
+func (s *Service) SavePlaylist(ctx context.Context, name string, tracks []spotify.SimpleTrack, opts SavePlaylistOptions) (*Playlist, error) {
+	// Get current user
+	user, err := s.client.CurrentUser(ctx)
+	if err != nil {
+		return nil, errs.Classify(fmt.Errorf("failed to get current user: %w", err))
+	}
+
+	// Create playlist description
+	description := opts.Description
+	if description == "" {
+		description = fmt.Sprintf("Generated by Moodify - %d tracks discovered through natural language search", len(tracks))
+	}
+
+	// Convert SimpleTrack to track IDs for playlist addition
+	var trackIDs []spotify.ID
+	for _, track := range tracks {
+		// Extract ID from URI (format: spotify:track:ID)
+		uriParts := strings.Split(string(track.URI), ":")
+		if len(uriParts) >= 3 {
+			trackIDs = append(trackIDs, spotify.ID(uriParts[2]))
+		}
+	}
+
+	if len(trackIDs) == 0 {
+		return nil, fmt.Errorf("no valid track IDs found")
+	}
+
+	// Create playlist
+	playlist, err := s.client.CreatePlaylistForUser(ctx, user.ID, name, description, opts.Public, false)
+	if err != nil {
+		return nil, errs.Classify(fmt.Errorf("failed to create playlist: %w", err))
+	}
+
+	// Add tracks to playlist (Spotify API limits to 100 tracks per request)
+	if err := spotifyx.AddTracksInBatches(ctx, s.client, playlist.ID, trackIDs); err != nil {
+		return nil, errs.Classify(err)
+	}
+
+	saved := NewPlaylist(playlist.SimplePlaylist, user.ID)
+	saved.Tracks = len(trackIDs)
+	return &saved, nil
+}
+
+// PlaylistOptions filter and limit Playlists
+type PlaylistOptions struct {
+	Public  bool // only public playlists
+	Private bool // only private playlists
+	All     bool // include playlists the user follows
+	Limit   int  // most playlists to return (default 20)
+	Every   bool // ignore Limit and return every match
+}
+
+// Playlists lists the user's playlists matching opts
+func (s *Service) Playlists(ctx context.Context, opts PlaylistOptions) (*PlaylistList, error) {
+	if opts.Limit < 1 {
+		opts.Limit = 20
+	}
+
+	user, err := s.client.CurrentUser(ctx)
+	if err != nil {
+		return nil, errs.Classify(fmt.Errorf("failed to get user info: %w", err))
+	}
+
+	// Get user's playlists, following pages until we have enough matches.
+	// One extra match tells us whether there are more to show.
+	page, err := s.client.CurrentUsersPlaylists(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, errs.Classify(fmt.Errorf("failed to get playlists: %w", err))
+	}
+
+	listing := &PlaylistList{
+		User:      user.DisplayName,
+		Total:     int(page.Total),
+		Playlists: []Playlist{},
+	}
+	if page.Total == 0 {
+		return listing, nil
+	}
+
+	pageOpts := spotifyx.PageOptions[spotify.SimplePlaylist]{
+		Keep: func(playlist spotify.SimplePlaylist) bool {
+			// Apply visibility filters
+			if opts.Public && !playlist.IsPublic {
+				return false
+			}
+			if opts.Private && playlist.IsPublic {
+				return false
+			}
+
+			// Apply ownership filter (if not showing all)
+			return opts.All || playlist.Owner.ID == user.ID
+		},
+	}
+	if !opts.Every {
+		pageOpts.Max = opts.Limit + 1
+	}
+
+	filteredPlaylists, err := spotifyx.Collect(ctx, spotifyx.PlaylistPages(s.client, page), pageOpts)
+	if err != nil {
+		return nil, errs.Classify(fmt.Errorf("failed to get playlists: %w", err))
+	}
+
+	listing.HasMore = !opts.Every && len(filteredPlaylists) > opts.Limit
+	if listing.HasMore {
+		filteredPlaylists = filteredPlaylists[:opts.Limit]
+	}
+
+	for _, playlist := range filteredPlaylists {
+		listing.Playlists = append(listing.Playlists, NewPlaylist(playlist, user.ID))
+	}
+	return listing, nil
+}
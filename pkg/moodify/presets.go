@@ -0,0 +1,53 @@
+package moodify
+
+import (
+	"context"
+
+	"github.com/lorrehuggan/moodify/internal/preset"
+	"github.com/zmb3/spotify/v2"
+)
+
+// LoadPreset returns the saved preset with a name or alias
+func LoadPreset(name string) (*Preset, error) {
+	store, err := preset.Open()
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(name)
+}
+
+// ParsePreset returns what the preset's vibe was parsed into, without parsing
+// it again
+func ParsePreset(p *Preset) *ParseResult {
+	query := p.Query
+	if query == "" {
+		query = p.Name
+	}
+	return &ParseResult{Query: query, Parser: ParserPreset, Filters: p.Filters}
+}
+
+// PresetSeeds returns the preset's seeds, or nil when it has none and seeds
+// should be built from its filters
+func PresetSeeds(p *Preset) *spotify.Seeds {
+	if p.Seeds.Empty() {
+		return nil
+	}
+	seeds := p.Seeds.Spotify()
+	return &seeds
+}
+
+// NewPreset parses a vibe and builds its seeds, for saving under a name
+func (s *Service) NewPreset(ctx context.Context, name, query string) (*Preset, *ParseResult) {
+	parsed := Parse(ctx, query)
+	seeds := s.Seeds(ctx, parsed.Filters)
+
+	p := &Preset{Name: name, Query: query, Filters: parsed.Filters}
+	p.Seeds.Genres = seeds.Genres
+	for _, id := range seeds.Artists {
+		p.Seeds.Artists = append(p.Seeds.Artists, string(id))
+	}
+	for _, id := range seeds.Tracks {
+		p.Seeds.Tracks = append(p.Seeds.Tracks, string(id))
+	}
+	return p, parsed
+}
@@ -0,0 +1,276 @@
+package moodify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/errs"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Parsers reported by Parse
+const (
+	ParserAI       = "ai"       // OpenAI, used when OPENAI_API_KEY is set
+	ParserKeywords = "keywords" // built-in keyword matching
+	ParserPreset   = "preset"   // filters and seeds from a saved preset
+)
+
+// ParseResult is a vibe parsed into filters
+type ParseResult struct {
+	Query   string  `json:"query"`
+	Parser  string  `json:"parser"` // ParserAI, ParserKeywords or ParserPreset
+	Filters Filters `json:"filters"`
+
+	// AIError is why AI parsing failed when it fell back to keywords
+	AIError error `json:"-"`
+}
+
+// Parse turns a free text vibe into filters, using AI when OPENAI_API_KEY is
+// set and falling back to keyword matching otherwise
+func Parse(ctx context.Context, query string) *ParseResult {
+	result := &ParseResult{Query: query, Parser: ParserKeywords}
+	filters, err := ai.ParseQuery(ctx, query)
+	switch {
+	case err != nil:
+		result.AIError = err
+		filters = ai.SimpleParse(query)
+	case os.Getenv("OPENAI_API_KEY") != "":
+		result.Parser = ParserAI
+	}
+	result.Filters = filters
+	return result
+}
+
+// SearchOptions tune Search
+type SearchOptions struct {
+	Limit   int            // tracks to return, 1-100 (default 15); ignored when fitting
+	Filters *Filters       // use these instead of parsing the query
+	Seeds   *spotify.Seeds // use these instead of building seeds from the filters
+	Fit     Fit            // fill a duration instead of returning Limit tracks
+}
+
+// Search finds tracks matching a natural language query
+func (s *Service) Search(ctx context.Context, query string, opts SearchOptions) (*SearchResult, error) {
+	limit := opts.Limit
+	if limit < 1 || limit > 100 {
+		limit = 15
+	}
+
+	var filters Filters
+	if opts.Filters != nil {
+		filters = *opts.Filters
+	} else {
+		filters = Parse(ctx, query).Filters
+	}
+
+	var seeds spotify.Seeds
+	if opts.Seeds != nil {
+		seeds = *opts.Seeds
+	} else {
+		seeds = s.Seeds(ctx, filters)
+	}
+	tracks, err := s.Recommend(ctx, query, filters, seeds, opts.Fit.candidates(limit))
+	if err != nil {
+		return nil, errs.Classify(err)
+	}
+
+	// Pick tracks to fill the target length if one was given
+	if tracks, err = FitTracks(tracks, opts.Fit); err != nil {
+		return nil, errs.Classify(err)
+	}
+	result := NewSearchResult(query, filters, tracks)
+	result.Seeds = seeds
+	return result, nil
+}
+
+// Recommend gets recommendations for the filters, falling back to a plain
+// search, and drops tracks outside the year range or excluded genres
+func (s *Service) Recommend(ctx context.Context, query string, filters Filters, seeds spotify.Seeds, limit int) ([]spotify.SimpleTrack, error) {
+	var tracks []spotify.SimpleTrack
+	recs, err := spotifyx.GetRecommendationsWithFilters(ctx, s.client, seeds,
+		filters.MinDanceability, filters.MaxDanceability,
+		filters.MinEnergy, filters.MaxEnergy,
+		filters.MinValence, filters.MaxValence,
+		filters.MinTempo, filters.MaxTempo,
+		filters.MinPopularity, filters.MaxPopularity,
+		limit, s.market)
+
+	if err != nil {
+		// Fallback to search-based approach
+		searchResults, searchErr := s.SearchTracks(ctx, query, filters, limit)
+		if searchErr != nil {
+			return nil, errs.Classify(fmt.Errorf("recommendations failed (%v), and so did the search fallback: %w", err, searchErr))
+		}
+		tracks = searchResults
+	} else {
+		tracks = recs.Tracks
+	}
+
+	// Optional: post-filter by release year if user mentioned an era (if not already done in fallback)
+	tracks = FilterByYear(tracks, filters)
+
+	if len(filters.ExcludeGenres) > 0 {
+		if tracks, err = spotifyx.ExcludeArtistGenres(ctx, s.client, tracks, filters.ExcludeGenres); err != nil {
+			return nil, errs.Classify(err)
+		}
+	}
+	return tracks, nil
+}
+
+// Seeds builds recommendation seeds from the parsed genres, falling back to
+// the user's top artists and then to pop
+func (s *Service) Seeds(ctx context.Context, filters Filters) spotify.Seeds {
+	seeds := spotify.Seeds{
+		// Smart defaults: prefer up to 5 total across artists/genres/tracks
+		Genres: filters.Genres, // []string
+	}
+
+	// Validate and clean up genres - remove any that might be invalid
+	validGenres := ValidGenres(seeds.Genres)
+	seeds.Genres = validGenres
+
+	// If no valid genres from parsing, seed by user's top artists as a nice fallback:
+	if len(seeds.Genres) == 0 {
+		top, err := s.client.CurrentUsersTopArtists(ctx, spotify.Limit(3))
+		if err == nil && len(top.Artists) > 0 {
+			for i, a := range top.Artists {
+				if i >= 2 { // Limit to 2 artist seeds to leave room for genres if needed
+					break
+				}
+				seeds.Artists = append(seeds.Artists, a.ID)
+			}
+		}
+	}
+
+	// Ensure we have at least one seed - use popular genres as last resort
+	totalSeeds := len(seeds.Genres) + len(seeds.Artists) + len(seeds.Tracks)
+	if totalSeeds == 0 {
+		seeds.Genres = []string{"pop"}
+	}
+
+	// Ensure we don't exceed Spotify's limit of 5 seeds total
+	if totalSeeds > 5 {
+		if len(seeds.Genres) > 3 {
+			seeds.Genres = seeds.Genres[:3]
+		}
+		if len(seeds.Artists) > 2 {
+			seeds.Artists = seeds.Artists[:2]
+		}
+	}
+
+	return seeds
+}
+
+// ValidGenres keeps the genres Spotify accepts as recommendation seeds
+func ValidGenres(genres []string) []string {
+	// Known good Spotify recommendation genres (a subset of commonly used ones)
+	validGenres := map[string]bool{
+		"acoustic": true, "afrobeat": true, "alt-rock": true, "alternative": true,
+		"ambient": true, "blues": true, "bossanova": true, "brazil": true,
+		"breakbeat": true, "british": true, "chill": true, "classical": true,
+		"club": true, "country": true, "dance": true, "dancehall": true,
+		"deep-house": true, "disco": true, "drum-and-bass": true, "dub": true,
+		"dubstep": true, "edm": true, "electronic": true, "folk": true,
+		"funk": true, "garage": true, "gospel": true, "groove": true,
+		"hip-hop": true, "house": true, "indie": true, "indie-pop": true,
+		"jazz": true, "latin": true, "metal": true, "pop": true,
+		"punk": true, "r-n-b": true, "reggae": true, "rock": true,
+		"soul": true, "techno": true, "trance": true, "world-music": true,
+	}
+
+	var result []string
+	for _, genre := range genres {
+		if validGenres[strings.ToLower(genre)] {
+			result = append(result, strings.ToLower(genre))
+		}
+	}
+
+	return result
+}
+
+// SearchTracks uses Spotify's search API to find tracks for a query and
+// filters, for when recommendations aren't available
+func (s *Service) SearchTracks(ctx context.Context, originalQuery string, filters Filters, limit int) ([]spotify.SimpleTrack, error) {
+	// Build search query based on parsed filters
+	searchQuery := buildSearchQuery(originalQuery, filters)
+
+	// Search for tracks
+	// Get more results to filter, within the search API's maximum of 50
+	results, err := s.client.Search(ctx, searchQuery, spotify.SearchTypeTrack, spotify.Limit(min(limit*2, 50)))
+	if err != nil {
+		return nil, errs.Classify(fmt.Errorf("search failed: %w", err))
+	}
+
+	if len(results.Tracks.Tracks) == 0 {
+		return nil, fmt.Errorf("no tracks found")
+	}
+
+	// Convert FullTrack to SimpleTrack and apply filters
+	var tracks []spotify.SimpleTrack
+	for _, fullTrack := range results.Tracks.Tracks {
+		// Convert to SimpleTrack
+		simpleTrack := spotify.SimpleTrack{
+			Artists: make([]spotify.SimpleArtist, len(fullTrack.Artists)),
+			Album: spotify.SimpleAlbum{
+				Name:        fullTrack.Album.Name,
+				ReleaseDate: fullTrack.Album.ReleaseDate,
+				ID:          fullTrack.Album.ID,
+			},
+			Duration:     fullTrack.Duration,
+			ExternalURLs: fullTrack.ExternalURLs,
+			ID:           fullTrack.ID,
+			Name:         fullTrack.Name,
+			URI:          fullTrack.URI,
+		}
+
+		// Convert artists
+		for i, artist := range fullTrack.Artists {
+			simpleTrack.Artists[i] = spotify.SimpleArtist{
+				ID:   artist.ID,
+				Name: artist.Name,
+			}
+		}
+
+		// Apply year filter if specified
+		if filters.YearStart > 0 || filters.YearEnd > 0 {
+			year := spotifyx.ParseYear(simpleTrack.Album.ReleaseDate)
+			if (filters.YearStart > 0 && year < filters.YearStart) ||
+				(filters.YearEnd > 0 && year > filters.YearEnd) {
+				continue // Skip tracks outside year range
+			}
+		}
+
+		tracks = append(tracks, simpleTrack)
+		if len(tracks) >= limit {
+			break // We have enough tracks
+		}
+	}
+
+	return tracks, nil
+}
+
+// buildSearchQuery creates a search string from the original query and parsed filters
+func buildSearchQuery(originalQuery string, filters Filters) string {
+	query := originalQuery
+
+	// Add genre information to search if available
+	if len(filters.Genres) > 0 {
+		// Add the first genre to the search query
+		query += " genre:" + filters.Genres[0]
+	}
+
+	// Add year range if specified
+	if filters.YearStart > 0 && filters.YearEnd > 0 {
+		query += fmt.Sprintf(" year:%d-%d", filters.YearStart, filters.YearEnd)
+	} else if filters.YearStart > 0 {
+		query += fmt.Sprintf(" year:%d-2024", filters.YearStart)
+	} else if filters.YearEnd > 0 {
+		query += fmt.Sprintf(" year:1950-%d", filters.YearEnd)
+	}
+
+	return query
+}
@@ -0,0 +1,200 @@
+package moodify
+
+import (
+	"time"
+
+	"github.com/lorrehuggan/moodify/internal/ai"
+	"github.com/lorrehuggan/moodify/internal/fit"
+	"github.com/lorrehuggan/moodify/internal/preset"
+	spotifyx "github.com/lorrehuggan/moodify/internal/spotify"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Filters are the audio attributes, genres and era a vibe was parsed into
+type Filters = ai.Filters
+
+// Preset is a named mood: filters and seeds saved with 'moodify preset save'
+type Preset = preset.Preset
+
+// Ways Fit.Method picks tracks to fill a duration
+const (
+	BestFit  = fit.BestFit  // closest total to the target, reordering as needed
+	FirstFit = fit.FirstFit // keep the original order, skipping tracks that overshoot
+)
+
+// Fit asks for tracks filling a total duration instead of a fixed count
+type Fit struct {
+	Target    time.Duration // total length to fill; zero disables fitting
+	Tolerance time.Duration // how far the total may be from Target
+	Method    string        // BestFit (default) or FirstFit
+}
+
+// fitCandidates is how many tracks are fetched to choose from when fitting a
+// duration (the most Spotify returns in one recommendations call)
+const fitCandidates = 100
+
+// candidates returns how many tracks to request to fill the target, or limit
+// when there is no target
+func (f Fit) candidates(limit int) int {
+	if f.Target > 0 {
+		return fitCandidates
+	}
+	return limit
+}
+
+// FitTracks picks tracks whose total length fills f.Target, keeping their
+// order. Without a target the tracks are returned unchanged.
+func FitTracks(tracks []spotify.SimpleTrack, f Fit) ([]spotify.SimpleTrack, error) {
+	if f.Target <= 0 {
+		return tracks, nil
+	}
+	if f.Method == "" {
+		f.Method = BestFit
+	}
+
+	durations := make([]time.Duration, 0, len(tracks))
+	for _, t := range tracks {
+		durations = append(durations, t.TimeDuration())
+	}
+
+	result, err := fit.Select(durations, fit.Options{
+		Target:    f.Target,
+		Tolerance: f.Tolerance,
+		Method:    f.Method,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]spotify.SimpleTrack, 0, len(result.Indexes))
+	for _, i := range result.Indexes {
+		selected = append(selected, tracks[i])
+	}
+	return selected, nil
+}
+
+// Runtime sums the length of the tracks
+func Runtime(tracks []spotify.SimpleTrack) time.Duration {
+	var total time.Duration
+	for _, t := range tracks {
+		total += t.TimeDuration()
+	}
+	return total
+}
+
+// FilterByYear keeps tracks released within the filters' year range
+func FilterByYear(tracks []spotify.SimpleTrack, filters Filters) []spotify.SimpleTrack {
+	if filters.YearStart == 0 && filters.YearEnd == 0 {
+		return tracks
+	}
+
+	filtered := make([]spotify.SimpleTrack, 0, len(tracks))
+	for _, t := range tracks {
+		yr := spotifyx.ParseYear(t.Album.ReleaseDate)
+		if (filters.YearStart == 0 || yr >= filters.YearStart) &&
+			(filters.YearEnd == 0 || yr <= filters.YearEnd) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// Track is a track in search, discover and playlist results
+type Track struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Artists    []string `json:"artists"`
+	Album      string   `json:"album"`
+	Year       int      `json:"year,omitempty"`
+	DurationMs int      `json:"duration_ms"`
+	URI        string   `json:"uri"`
+	URL        string   `json:"url,omitempty"`
+}
+
+// NewTracks converts Spotify tracks to Tracks
+func NewTracks(tracks []spotify.SimpleTrack) []Track {
+	results := make([]Track, 0, len(tracks))
+	for _, t := range tracks {
+		artists := make([]string, 0, len(t.Artists))
+		for _, a := range t.Artists {
+			artists = append(artists, a.Name)
+		}
+		results = append(results, Track{
+			ID:         string(t.ID),
+			Name:       t.Name,
+			Artists:    artists,
+			Album:      t.Album.Name,
+			Year:       spotifyx.ParseYear(t.Album.ReleaseDate),
+			DurationMs: int(t.Duration),
+			URI:        string(t.URI),
+			URL:        t.ExternalURLs["spotify"],
+		})
+	}
+	return results
+}
+
+// SearchResult is what a search found
+type SearchResult struct {
+	Query     string  `json:"query"`
+	Filters   Filters `json:"filters"`
+	Tracks    []Track `json:"tracks"`
+	RuntimeMs int64   `json:"runtime_ms"`
+
+	// Items are the Spotify tracks behind Tracks, for playback and playlists
+	Items []spotify.SimpleTrack `json:"-"`
+	// Seeds are the recommendation seeds used, when Search made the result
+	Seeds spotify.Seeds `json:"-"`
+}
+
+// NewSearchResult describes tracks found for a query
+func NewSearchResult(query string, filters Filters, tracks []spotify.SimpleTrack) *SearchResult {
+	return &SearchResult{
+		Query:     query,
+		Filters:   filters,
+		Tracks:    NewTracks(tracks),
+		RuntimeMs: Runtime(tracks).Milliseconds(),
+		Items:     tracks,
+	}
+}
+
+// Playlist is a playlist in playlist listings
+type Playlist struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Owner         string `json:"owner"`
+	Owned         bool   `json:"owned"`
+	Public        bool   `json:"public"`
+	Collaborative bool   `json:"collaborative"`
+	Tracks        int    `json:"tracks"`
+	URI           string `json:"uri"`
+	URL           string `json:"url,omitempty"`
+}
+
+// NewPlaylist converts a Spotify playlist; userID marks playlists the user owns
+func NewPlaylist(playlist spotify.SimplePlaylist, userID string) Playlist {
+	owner := playlist.Owner.DisplayName
+	if owner == "" {
+		owner = playlist.Owner.ID
+	}
+	return Playlist{
+		ID:            string(playlist.ID),
+		Name:          playlist.Name,
+		Description:   playlist.Description,
+		Owner:         owner,
+		Owned:         playlist.Owner.ID == userID,
+		Public:        playlist.IsPublic,
+		Collaborative: playlist.Collaborative,
+		Tracks:        int(playlist.Tracks.Total),
+		URI:           string(playlist.URI),
+		URL:           playlist.ExternalURLs["spotify"],
+	}
+}
+
+// PlaylistList is a page of the user's playlists
+type PlaylistList struct {
+	User      string     `json:"user"`
+	Total     int        `json:"total"` // playlists in the library, before filtering
+	HasMore   bool       `json:"has_more"`
+	Playlists []Playlist `json:"playlists"`
+}